@@ -0,0 +1,141 @@
+package altnrslog
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// adminHandler is the [http.Handler] returned by [NewAdminHandler].
+type adminHandler struct {
+	c *LevelController
+}
+
+// levelRequest is the request body to set a level.
+type levelRequest struct {
+	Level *slog.Level `json:"level"`
+}
+
+// debugRequest is the request body to activate the temporary debug mode.
+type debugRequest struct {
+	Duration string `json:"duration"`
+}
+
+// NewAdminHandler returns the [http.Handler] to inspect and change the levels controlled by c at runtime.
+// The paths are relative to the mount point, so it is intended to be mounted with [http.StripPrefix].
+//
+//	GET    /                 returns the LevelState as JSON.
+//	PUT    /                 sets the global level by {"level": "debug"}.
+//	PUT    /overrides/{name} sets the level of the logger named name by {"level": "debug"}.
+//	DELETE /overrides/{name} removes the level override of the logger named name.
+//	PUT    /debug            activates the temporary debug mode by {"duration": "15m"}.
+//	DELETE /debug            deactivates the temporary debug mode.
+//
+// All the successful requests are responded with the LevelState after the change.
+// It does not authenticate the requests, so it must not be exposed publicly.
+func NewAdminHandler(c *LevelController) http.Handler {
+	return &adminHandler{c: c}
+}
+
+// ServeHTTP See: [http.Handler.ServeHTTP]
+func (h *adminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := "/" + strings.TrimPrefix(r.URL.Path, "/")
+	switch {
+	case path == "/":
+		h.serveGlobal(w, r)
+	case strings.HasPrefix(path, "/overrides/") && len(path) > len("/overrides/"):
+		h.serveOverride(w, r, strings.TrimPrefix(path, "/overrides/"))
+	case path == "/debug":
+		h.serveDebug(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// serveGlobal serves the global level.
+func (h *adminHandler) serveGlobal(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		level, err := decodeLevel(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.c.SetLevel(level)
+	default:
+		methodNotAllowed(w, http.MethodGet, http.MethodPut)
+		return
+	}
+	h.writeState(w)
+}
+
+// serveOverride serves the level override of the logger named name.
+func (h *adminHandler) serveOverride(w http.ResponseWriter, r *http.Request, name string) {
+	switch r.Method {
+	case http.MethodPut:
+		level, err := decodeLevel(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.c.SetOverride(name, level)
+	case http.MethodDelete:
+		h.c.RemoveOverride(name)
+	default:
+		methodNotAllowed(w, http.MethodPut, http.MethodDelete)
+		return
+	}
+	h.writeState(w)
+}
+
+// serveDebug serves the temporary debug mode.
+func (h *adminHandler) serveDebug(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPut:
+		var req debugRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		d, err := time.ParseDuration(req.Duration)
+		if err != nil || d <= 0 {
+			http.Error(w, "duration must be a positive duration such as 15m", http.StatusBadRequest)
+			return
+		}
+		h.c.DebugFor(d)
+	case http.MethodDelete:
+		h.c.DebugFor(0)
+	default:
+		methodNotAllowed(w, http.MethodPut, http.MethodDelete)
+		return
+	}
+	h.writeState(w)
+}
+
+// writeState writes the current state as JSON.
+func (h *adminHandler) writeState(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.c.State())
+}
+
+// decodeLevel decodes the level from the request body.
+func decodeLevel(r *http.Request) (slog.Level, error) {
+	var req levelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return 0, err
+	}
+	if req.Level == nil {
+		return 0, errors.New("level is required")
+	}
+	return *req.Level, nil
+}
+
+// methodNotAllowed responds with 405 Method Not Allowed.
+func methodNotAllowed(w http.ResponseWriter, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+}
@@ -0,0 +1,134 @@
+package altnrslog
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNewAdminHandler(t *testing.T) {
+	type args struct {
+		method string
+		path   string
+		body   string
+	}
+	type want struct {
+		status int
+		state  *LevelState
+		debug  bool
+	}
+	type test struct {
+		args []args
+		want want
+	}
+	tests := map[string]test{
+		"happy-path: get": {
+			args: []args{{method: http.MethodGet, path: "/"}},
+			want: want{
+				status: http.StatusOK,
+				state:  &LevelState{Level: slog.LevelInfo, Overrides: map[string]slog.Level{}},
+			},
+		},
+		"happy-path: put global level": {
+			args: []args{{method: http.MethodPut, path: "/", body: `{"level": "warn"}`}},
+			want: want{
+				status: http.StatusOK,
+				state:  &LevelState{Level: slog.LevelWarn, Overrides: map[string]slog.Level{}},
+			},
+		},
+		"happy-path: put and delete overrides": {
+			args: []args{
+				{method: http.MethodPut, path: "/overrides/billing", body: `{"level": "DEBUG"}`},
+				{method: http.MethodPut, path: "/overrides/shipping", body: `{"level": "ERROR"}`},
+				{method: http.MethodDelete, path: "/overrides/shipping"},
+			},
+			want: want{
+				status: http.StatusOK,
+				state:  &LevelState{Level: slog.LevelInfo, Overrides: map[string]slog.Level{"billing": slog.LevelDebug}},
+			},
+		},
+		"happy-path: put debug": {
+			args: []args{{method: http.MethodPut, path: "/debug", body: `{"duration": "15m"}`}},
+			want: want{
+				status: http.StatusOK,
+				debug:  true,
+			},
+		},
+		"happy-path: delete debug": {
+			args: []args{
+				{method: http.MethodPut, path: "/debug", body: `{"duration": "15m"}`},
+				{method: http.MethodDelete, path: "/debug"},
+			},
+			want: want{
+				status: http.StatusOK,
+				state:  &LevelState{Level: slog.LevelInfo, Overrides: map[string]slog.Level{}},
+			},
+		},
+		"unhappy-path: invalid level": {
+			args: []args{{method: http.MethodPut, path: "/", body: `{"level": "verbose"}`}},
+			want: want{status: http.StatusBadRequest},
+		},
+		"unhappy-path: missing level": {
+			args: []args{{method: http.MethodPut, path: "/overrides/billing", body: `{}`}},
+			want: want{status: http.StatusBadRequest},
+		},
+		"unhappy-path: invalid duration": {
+			args: []args{{method: http.MethodPut, path: "/debug", body: `{"duration": "-1m"}`}},
+			want: want{status: http.StatusBadRequest},
+		},
+		"unhappy-path: method not allowed": {
+			args: []args{{method: http.MethodPost, path: "/"}},
+			want: want{status: http.StatusMethodNotAllowed},
+		},
+		"unhappy-path: not found": {
+			args: []args{{method: http.MethodGet, path: "/overrides/"}},
+			want: want{status: http.StatusNotFound},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := NewLevelController(nil)
+			srv := httptest.NewServer(http.StripPrefix("/admin/log", NewAdminHandler(c)))
+			defer srv.Close()
+
+			var res *http.Response
+			for _, a := range tt.args {
+				req, err := http.NewRequest(a.method, srv.URL+"/admin/log"+a.path, strings.NewReader(a.body))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if res, err = srv.Client().Do(req); err != nil {
+					t.Fatal(err)
+				}
+				defer res.Body.Close()
+			}
+			if res.StatusCode != tt.want.status {
+				t.Fatalf("status = %d, want %d", res.StatusCode, tt.want.status)
+			}
+			if res.StatusCode != http.StatusOK {
+				return
+			}
+			var got LevelState
+			if err := json.NewDecoder(res.Body).Decode(&got); err != nil {
+				t.Fatal(err)
+			}
+			if (got.DebugUntil != nil) != tt.want.debug {
+				t.Errorf("DebugUntil = %v, want debug mode %v", got.DebugUntil, tt.want.debug)
+			}
+			if tt.want.debug && c.Level() != slog.LevelDebug {
+				t.Errorf("Level() = %v, want %v", c.Level(), slog.LevelDebug)
+			}
+			if tt.want.state == nil {
+				return
+			}
+			if diff := cmp.Diff(got, *tt.want.state); diff != "" {
+				t.Errorf("(-got, +want)\n%s", diff)
+			}
+		})
+	}
+}
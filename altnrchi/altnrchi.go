@@ -0,0 +1,31 @@
+// Package altnrchi integrates altnrslog with [chi].
+//
+// It is a separate module, so that the applications not using chi do not depend on it.
+//
+// [chi]: https://github.com/go-chi/chi
+package altnrchi
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/miyamo2/altnrslog"
+)
+
+// Middleware returns the chi middleware that stores [*slog.Logger] with [*altnrslog.TransactionalHandler]
+// created by factory in the request context, bound to the [newrelic.Transaction] placed by [newrelic.WrapHandle]
+// or other New Relic integrations.
+//
+// [newrelic.Transaction]: https://pkg.go.dev/github.com/newrelic/go-agent/v3/newrelic#Transaction
+// [newrelic.WrapHandle]: https://pkg.go.dev/github.com/newrelic/go-agent/v3/newrelic#WrapHandle
+func Middleware(factory *altnrslog.HandlerFactory) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return altnrslog.LoggerMiddleware(next, factory)
+	}
+}
+
+// Logger returns [*slog.Logger] stored by [Middleware] in the context of r.
+// If it does not exist, [slog.Default] is returned.
+func Logger(r *http.Request) *slog.Logger {
+	return altnrslog.LoggerOrDefault(r.Context())
+}
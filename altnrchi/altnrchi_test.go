@@ -0,0 +1,60 @@
+package altnrchi
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/miyamo2/altnrslog"
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+func TestMiddleware(t *testing.T) {
+	app, err := newrelic.NewApplication(
+		newrelic.ConfigAppName("altnrchi-test"),
+		newrelic.ConfigLicense("0123456789012345678901234567890123456789"),
+		newrelic.ConfigEnabled(false),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := &bytes.Buffer{}
+	factory := altnrslog.NewHandlerFactory(app, altnrslog.WithInnerWriter(buf), altnrslog.WithSlogHandlerSpecify(true, nil))
+
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		_, h := newrelic.WrapHandle(app, "/hello", next)
+		return h
+	})
+	r.Use(Middleware(factory))
+	var got *newrelic.Transaction
+	r.Get("/hello", func(w http.ResponseWriter, r *http.Request) {
+		logger := Logger(r)
+		if h, ok := logger.Handler().(*altnrslog.TransactionalHandler); ok {
+			got = h.Transaction()
+		}
+		logger.InfoContext(r.Context(), "hello")
+	})
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/hello", nil))
+
+	if got == nil {
+		t.Fatal("logger not bound to the transaction")
+	}
+	var line map[string]any
+	if err := json.Unmarshal(bytes.SplitN(buf.Bytes(), []byte("\n"), 2)[0], &line); err != nil {
+		t.Fatal(err)
+	}
+	if line[slog.MessageKey] != "hello" {
+		t.Errorf("msg = %v, want %v", line[slog.MessageKey], "hello")
+	}
+}
+
+func TestLogger_WithoutMiddleware(t *testing.T) {
+	if got := Logger(httptest.NewRequest(http.MethodGet, "/", nil)); got != slog.Default() {
+		t.Errorf("Logger() = %p, want %p", got, slog.Default())
+	}
+}
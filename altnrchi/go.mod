@@ -0,0 +1,22 @@
+module github.com/miyamo2/altnrslog/altnrchi
+
+go 1.21
+
+require (
+	github.com/go-chi/chi/v5 v5.1.0
+	github.com/miyamo2/altnrslog v0.4.0
+	github.com/newrelic/go-agent/v3 v3.33.1
+)
+
+require (
+	github.com/golang/protobuf v1.5.3 // indirect
+	go.opentelemetry.io/otel v1.24.0 // indirect
+	go.opentelemetry.io/otel/trace v1.24.0 // indirect
+	golang.org/x/net v0.23.0 // indirect
+	golang.org/x/sys v0.18.0 // indirect
+	golang.org/x/text v0.14.0 // indirect
+	google.golang.org/genproto v0.0.0-20230410155749-daa745c078e1 // indirect
+	google.golang.org/grpc v1.56.3 // indirect
+	google.golang.org/protobuf v1.33.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
@@ -0,0 +1,36 @@
+// Package altnrecho integrates altnrslog with [echo].
+//
+// It is a separate module, so that the applications not using echo do not depend on it.
+//
+// [echo]: https://github.com/labstack/echo
+package altnrecho
+
+import (
+	"log/slog"
+
+	"github.com/labstack/echo/v4"
+	"github.com/miyamo2/altnrslog"
+)
+
+// Middleware returns the echo middleware that stores [*slog.Logger] with [*altnrslog.TransactionalHandler]
+// created by factory in the request context, bound to the [newrelic.Transaction] placed by [nrecho.Middleware]
+// or other New Relic integrations.
+//
+// [newrelic.Transaction]: https://pkg.go.dev/github.com/newrelic/go-agent/v3/newrelic#Transaction
+// [nrecho.Middleware]: https://pkg.go.dev/github.com/newrelic/go-agent/v3/integrations/nrecho-v4#Middleware
+func Middleware(factory *altnrslog.HandlerFactory) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			r := c.Request()
+			ctx, _ := altnrslog.ContextWithLogger(r.Context(), factory)
+			c.SetRequest(r.WithContext(ctx))
+			return next(c)
+		}
+	}
+}
+
+// Logger returns [*slog.Logger] stored by [Middleware] in the request context of c.
+// If it does not exist, [slog.Default] is returned.
+func Logger(c echo.Context) *slog.Logger {
+	return altnrslog.LoggerOrDefault(c.Request().Context())
+}
@@ -0,0 +1,66 @@
+package altnrecho
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/miyamo2/altnrslog"
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+func TestMiddleware(t *testing.T) {
+	app, err := newrelic.NewApplication(
+		newrelic.ConfigAppName("altnrecho-test"),
+		newrelic.ConfigLicense("0123456789012345678901234567890123456789"),
+		newrelic.ConfigEnabled(false),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := &bytes.Buffer{}
+	factory := altnrslog.NewHandlerFactory(app, altnrslog.WithInnerWriter(buf), altnrslog.WithSlogHandlerSpecify(true, nil))
+
+	e := echo.New()
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			tx := app.StartTransaction(c.Path())
+			defer tx.End()
+			c.SetRequest(newrelic.RequestWithTransactionContext(c.Request(), tx))
+			return next(c)
+		}
+	})
+	e.Use(Middleware(factory))
+	var got *newrelic.Transaction
+	e.GET("/hello", func(c echo.Context) error {
+		logger := Logger(c)
+		if h, ok := logger.Handler().(*altnrslog.TransactionalHandler); ok {
+			got = h.Transaction()
+		}
+		logger.InfoContext(c.Request().Context(), "hello")
+		return nil
+	})
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/hello", nil))
+
+	if got == nil {
+		t.Fatal("logger not bound to the transaction")
+	}
+	var line map[string]any
+	if err := json.Unmarshal(bytes.SplitN(buf.Bytes(), []byte("\n"), 2)[0], &line); err != nil {
+		t.Fatal(err)
+	}
+	if line[slog.MessageKey] != "hello" {
+		t.Errorf("msg = %v, want %v", line[slog.MessageKey], "hello")
+	}
+}
+
+func TestLogger_WithoutMiddleware(t *testing.T) {
+	c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+	if got := Logger(c); got != slog.Default() {
+		t.Errorf("Logger() = %p, want %p", got, slog.Default())
+	}
+}
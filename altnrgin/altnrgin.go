@@ -0,0 +1,42 @@
+// Package altnrgin integrates altnrslog with [gin].
+//
+// It is a separate module, so that the applications not using gin do not depend on it.
+//
+// [gin]: https://github.com/gin-gonic/gin
+package altnrgin
+
+import (
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+	"github.com/miyamo2/altnrslog"
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+// Middleware returns the gin middleware that stores [*slog.Logger] with [*altnrslog.TransactionalHandler]
+// created by factory in the request context, bound to the [newrelic.Transaction] placed in the gin context by [nrgin.Middleware],
+// or in the request context by other New Relic integrations.
+//
+// [newrelic.Transaction]: https://pkg.go.dev/github.com/newrelic/go-agent/v3/newrelic#Transaction
+// [nrgin.Middleware]: https://pkg.go.dev/github.com/newrelic/go-agent/v3/integrations/nrgin#Middleware
+func Middleware(factory *altnrslog.HandlerFactory) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// nrgin.Middleware stores the transaction in the gin context, which newrelic.FromContext resolves by its string key.
+		tx := newrelic.FromContext(c)
+		if tx == nil {
+			tx = newrelic.FromContext(c.Request.Context())
+		}
+		ctx, _ := altnrslog.ContextWithTransactionLogger(c.Request.Context(), factory, tx)
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// Logger returns [*slog.Logger] stored by [Middleware] in the request context of c.
+// If it does not exist, [slog.Default] is returned.
+func Logger(c *gin.Context) *slog.Logger {
+	if c.Request == nil {
+		return slog.Default()
+	}
+	return altnrslog.LoggerOrDefault(c.Request.Context())
+}
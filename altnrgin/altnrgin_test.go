@@ -0,0 +1,69 @@
+package altnrgin
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/miyamo2/altnrslog"
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	app, err := newrelic.NewApplication(
+		newrelic.ConfigAppName("altnrgin-test"),
+		newrelic.ConfigLicense("0123456789012345678901234567890123456789"),
+		newrelic.ConfigEnabled(false),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := &bytes.Buffer{}
+	factory := altnrslog.NewHandlerFactory(app, altnrslog.WithInnerWriter(buf), altnrslog.WithSlogHandlerSpecify(true, nil))
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		tx := app.StartTransaction(c.FullPath())
+		defer tx.End()
+		// Place the transaction the same way as nrgin.Middleware.
+		c.Set("newRelicTransaction", tx)
+		c.Next()
+	})
+	r.Use(Middleware(factory))
+	var got *newrelic.Transaction
+	r.GET("/hello", func(c *gin.Context) {
+		logger := Logger(c)
+		if h, ok := logger.Handler().(*altnrslog.TransactionalHandler); ok {
+			got = h.Transaction()
+		}
+		logger.InfoContext(c.Request.Context(), "hello")
+	})
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/hello", nil))
+
+	if got == nil {
+		t.Fatal("logger not bound to the transaction")
+	}
+	var line map[string]any
+	if err := json.Unmarshal(bytes.SplitN(buf.Bytes(), []byte("\n"), 2)[0], &line); err != nil {
+		t.Fatal(err)
+	}
+	if line[slog.MessageKey] != "hello" {
+		t.Errorf("msg = %v, want %v", line[slog.MessageKey], "hello")
+	}
+}
+
+func TestLogger_WithoutMiddleware(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	if got := Logger(c); got != slog.Default() {
+		t.Errorf("Logger() = %p, want %p", got, slog.Default())
+	}
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	if got := Logger(c); got != slog.Default() {
+		t.Errorf("Logger() = %p, want %p", got, slog.Default())
+	}
+}
@@ -0,0 +1,7 @@
+// altnrslogtest provides utilities for testing code that logs with [altnrslog].
+//
+// It offers a [Recorder] to capture records in memory, a [FakeMetadataProvider] with deterministic trace and span IDs,
+// and assertion helpers, so logs in context can be verified without a real APM Agent.
+//
+// [altnrslog]: https://pkg.go.dev/github.com/miyamo2/altnrslog
+package altnrslogtest
@@ -0,0 +1,20 @@
+package altnrslogtest
+
+import (
+	"github.com/miyamo2/altnrslog"
+)
+
+// NewHandler creates a new [altnrslog.TransactionalHandler] that writes to a [Recorder]
+// with the linking metadata of a [FakeMetadataProvider].
+//
+// options are applied after the ones for the recorder and the provider,
+// so options such as [altnrslog.WithLogLevel] can be specified.
+func NewHandler(options ...altnrslog.HandlerOption) (*altnrslog.TransactionalHandler, *Recorder, *FakeMetadataProvider) {
+	rec := NewRecorder()
+	provider := NewFakeMetadataProvider()
+	opts := append([]altnrslog.HandlerOption{
+		altnrslog.WithInnerHandlerProvider(rec.Provider()),
+		altnrslog.WithMetadataProvider(provider),
+	}, options...)
+	return altnrslog.NewTransactionalHandler(nil, nil, opts...), rec, provider
+}
@@ -0,0 +1,26 @@
+package altnrslogtest
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/miyamo2/altnrslog"
+	"github.com/newrelic/go-agent/v3/integrations/logcontext"
+)
+
+func TestNewHandler(t *testing.T) {
+	h, rec, provider := NewHandler(altnrslog.WithLogLevel(slog.LevelDebug))
+	logger := slog.New(h)
+
+	logger.Debug("first", slog.String("foo", "bar"))
+	rec.AssertLogged(t, slog.LevelDebug, "first",
+		slog.String("foo", "bar"),
+		slog.String(logcontext.KeySpanID, FakeSpanID(1)))
+	rec.AssertLinked(t, provider.GetLinkingMetadata())
+
+	spanID := provider.StartSpan()
+	rec.Reset()
+	logger.Info("second")
+	rec.AssertLogged(t, slog.LevelInfo, "second", slog.String(logcontext.KeySpanID, spanID))
+	rec.AssertLinked(t, provider.GetLinkingMetadata())
+}
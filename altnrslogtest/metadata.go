@@ -0,0 +1,70 @@
+package altnrslogtest
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+const (
+	// FakeTraceID is the trace ID provided by [FakeMetadataProvider].
+	FakeTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	// FakeEntityName is the entity name provided by [FakeMetadataProvider].
+	FakeEntityName = "altnrslogtest"
+	// FakeEntityType is the entity type provided by [FakeMetadataProvider].
+	FakeEntityType = "SERVICE"
+	// FakeEntityGUID is the entity GUID provided by [FakeMetadataProvider].
+	FakeEntityGUID = "MXxBUE18QVBQTElDQVRJT058MQ"
+	// FakeHostname is the hostname provided by [FakeMetadataProvider].
+	FakeHostname = "localhost"
+)
+
+// FakeSpanID returns the deterministic span ID of the n-th span provided by [FakeMetadataProvider].
+func FakeSpanID(n int) string {
+	return fmt.Sprintf("%016x", n)
+}
+
+// FakeMetadataProvider is an [altnrslog.MetadataProvider] that provides deterministic linking metadata.
+//
+// The span ID starts from FakeSpanID(1) and advances on each [FakeMetadataProvider.StartSpan].
+type FakeMetadataProvider struct {
+	mu   sync.Mutex
+	span int
+}
+
+// NewFakeMetadataProvider is constructor for [FakeMetadataProvider].
+func NewFakeMetadataProvider() *FakeMetadataProvider {
+	return &FakeMetadataProvider{span: 1}
+}
+
+// GetLinkingMetadata returns the deterministic linking metadata of the current span.
+func (p *FakeMetadataProvider) GetLinkingMetadata() newrelic.LinkingMetadata {
+	tm := p.GetTraceMetadata()
+	return newrelic.LinkingMetadata{
+		TraceID:    tm.TraceID,
+		SpanID:     tm.SpanID,
+		EntityName: FakeEntityName,
+		EntityType: FakeEntityType,
+		EntityGUID: FakeEntityGUID,
+		Hostname:   FakeHostname,
+	}
+}
+
+// GetTraceMetadata returns the deterministic trace metadata of the current span.
+func (p *FakeMetadataProvider) GetTraceMetadata() newrelic.TraceMetadata {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return newrelic.TraceMetadata{
+		TraceID: FakeTraceID,
+		SpanID:  FakeSpanID(p.span),
+	}
+}
+
+// StartSpan advances the current span, like starting a [newrelic.Segment], and returns the new span ID.
+func (p *FakeMetadataProvider) StartSpan() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.span++
+	return FakeSpanID(p.span)
+}
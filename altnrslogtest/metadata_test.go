@@ -0,0 +1,34 @@
+package altnrslogtest
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+func TestFakeMetadataProvider_GetLinkingMetadata(t *testing.T) {
+	sut := NewFakeMetadataProvider()
+	want := newrelic.LinkingMetadata{
+		TraceID:    FakeTraceID,
+		SpanID:     "0000000000000001",
+		EntityName: FakeEntityName,
+		EntityType: FakeEntityType,
+		EntityGUID: FakeEntityGUID,
+		Hostname:   FakeHostname,
+	}
+	if diff := cmp.Diff(sut.GetLinkingMetadata(), want); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestFakeMetadataProvider_StartSpan(t *testing.T) {
+	sut := NewFakeMetadataProvider()
+	if got := sut.StartSpan(); got != "0000000000000002" {
+		t.Errorf("StartSpan() = %s, want %s", got, "0000000000000002")
+	}
+	want := newrelic.TraceMetadata{TraceID: FakeTraceID, SpanID: "0000000000000002"}
+	if diff := cmp.Diff(sut.GetTraceMetadata(), want); diff != "" {
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,230 @@
+package altnrslogtest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/miyamo2/altnrslog"
+	"github.com/newrelic/go-agent/v3/integrations/logcontext"
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+// Entry is a log record captured by [Recorder].
+type Entry struct {
+	Time    time.Time
+	Level   slog.Level
+	Message string
+	// Attrs holds the attributes of the record.
+	// The keys of attributes in groups are qualified with the group names joined by ".".
+	Attrs map[string]slog.Value
+}
+
+// recorderState is the state shared by a [Recorder] and the handlers derived from it.
+type recorderState struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// Recorder is a [slog.Handler] that captures records in memory.
+//
+// It is safe for concurrent use.
+type Recorder struct {
+	state  *recorderState
+	attrs  map[string]slog.Value
+	groups []string
+}
+
+// NewRecorder is constructor for [Recorder].
+func NewRecorder() *Recorder {
+	return &Recorder{
+		state: &recorderState{},
+		attrs: map[string]slog.Value{},
+	}
+}
+
+// Provider returns an [altnrslog.InnerHandlerProvider] that provides the recorder.
+// The [io.Writer] given to the provider is not written to.
+func (r *Recorder) Provider() altnrslog.InnerHandlerProvider {
+	return func(io.Writer) slog.Handler {
+		return r
+	}
+}
+
+// Enabled See: [slog.Handler.Enabled]
+func (r *Recorder) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+// Handle captures the record.
+func (r *Recorder) Handle(_ context.Context, record slog.Record) error {
+	attrs := make(map[string]slog.Value, len(r.attrs)+record.NumAttrs())
+	for k, v := range r.attrs {
+		attrs[k] = v
+	}
+	prefix := strings.Join(r.groups, ".")
+	record.Attrs(func(a slog.Attr) bool {
+		flatten(attrs, prefix, a)
+		return true
+	})
+
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+	r.state.entries = append(r.state.entries, Entry{
+		Time:    record.Time,
+		Level:   record.Level,
+		Message: record.Message,
+		Attrs:   attrs,
+	})
+	return nil
+}
+
+// WithAttrs See: [slog.Handler.WithAttrs]
+func (r *Recorder) WithAttrs(attrs []slog.Attr) slog.Handler {
+	derived := r.clone()
+	prefix := strings.Join(r.groups, ".")
+	for _, a := range attrs {
+		flatten(derived.attrs, prefix, a)
+	}
+	return derived
+}
+
+// WithGroup See: [slog.Handler.WithGroup]
+func (r *Recorder) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return r
+	}
+	derived := r.clone()
+	derived.groups = append(derived.groups, name)
+	return derived
+}
+
+// clone returns a copy of the recorder sharing the captured entries.
+func (r *Recorder) clone() *Recorder {
+	attrs := make(map[string]slog.Value, len(r.attrs))
+	for k, v := range r.attrs {
+		attrs[k] = v
+	}
+	groups := make([]string, len(r.groups), len(r.groups)+1)
+	copy(groups, r.groups)
+	return &Recorder{
+		state:  r.state,
+		attrs:  attrs,
+		groups: groups,
+	}
+}
+
+// Entries returns the captured entries in the order they were handled.
+func (r *Recorder) Entries() []Entry {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+	entries := make([]Entry, len(r.state.entries))
+	copy(entries, r.state.entries)
+	return entries
+}
+
+// Reset discards the captured entries.
+func (r *Recorder) Reset() {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+	r.state.entries = nil
+}
+
+// AssertLogged asserts that a record with the level, message and all the attrs has been captured.
+// The keys of attrs in groups must be qualified with the group names joined by ".".
+func (r *Recorder) AssertLogged(t testing.TB, level slog.Level, msg string, attrs ...slog.Attr) {
+	t.Helper()
+	for _, e := range r.Entries() {
+		if e.Level == level && e.Message == msg && e.hasAttrs(attrs) {
+			return
+		}
+	}
+	t.Errorf("no record logged with level=%s msg=%q attrs=%v\n%s", level, msg, attrs, r.dump())
+}
+
+// AssertNotLogged asserts that no record with the level and message has been captured.
+func (r *Recorder) AssertNotLogged(t testing.TB, level slog.Level, msg string) {
+	t.Helper()
+	for _, e := range r.Entries() {
+		if e.Level == level && e.Message == msg {
+			t.Errorf("record logged with level=%s msg=%q\n%s", level, msg, r.dump())
+			return
+		}
+	}
+}
+
+// AssertLinked asserts that all the captured records carry the linking metadata md.
+func (r *Recorder) AssertLinked(t testing.TB, md newrelic.LinkingMetadata) {
+	t.Helper()
+	want := []slog.Attr{
+		slog.String(logcontext.KeyTraceID, md.TraceID),
+		slog.String(logcontext.KeySpanID, md.SpanID),
+		slog.String(logcontext.KeyEntityName, md.EntityName),
+		slog.String(logcontext.KeyEntityType, md.EntityType),
+		slog.String(logcontext.KeyEntityGUID, md.EntityGUID),
+		slog.String(logcontext.KeyHostname, md.Hostname),
+	}
+	for _, e := range r.Entries() {
+		if !e.hasAttrs(want) {
+			t.Errorf("record not linked to %+v: %s", md, e)
+		}
+	}
+}
+
+// hasAttrs reports whether the entry has all the attrs.
+func (e Entry) hasAttrs(attrs []slog.Attr) bool {
+	for _, a := range attrs {
+		v, ok := e.Attrs[a.Key]
+		if !ok || !v.Equal(a.Value.Resolve()) {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns a human-readable representation of the entry.
+func (e Entry) String() string {
+	return fmt.Sprintf("level=%s msg=%q attrs=%v", e.Level, e.Message, e.Attrs)
+}
+
+// dump returns the captured entries, one per line.
+func (r *Recorder) dump() string {
+	var b strings.Builder
+	b.WriteString("captured records:")
+	for _, e := range r.Entries() {
+		b.WriteString("\n\t")
+		b.WriteString(e.String())
+	}
+	return b.String()
+}
+
+// flatten adds a to attrs, qualifying the keys of attributes in groups with prefix.
+func flatten(attrs map[string]slog.Value, prefix string, a slog.Attr) {
+	v := a.Value.Resolve()
+	if v.Kind() != slog.KindGroup {
+		if a.Key == "" {
+			return
+		}
+		attrs[qualify(prefix, a.Key)] = v
+		return
+	}
+	if a.Key != "" {
+		prefix = qualify(prefix, a.Key)
+	}
+	for _, ga := range v.Group() {
+		flatten(attrs, prefix, ga)
+	}
+}
+
+// qualify joins prefix and key with ".".
+func qualify(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
@@ -0,0 +1,118 @@
+package altnrslogtest
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type fakeTB struct {
+	testing.TB
+	errors []string
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Errorf(format string, args ...any) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func TestRecorder_Handle(t *testing.T) {
+	sut := NewRecorder()
+	h := sut.WithAttrs([]slog.Attr{slog.String("foo", "bar")}).
+		WithGroup("baz").
+		WithAttrs([]slog.Attr{slog.Int("qux", 1)})
+
+	r := slog.NewRecord(time.Time{}, slog.LevelWarn, "msg", 0)
+	r.AddAttrs(slog.Group("quux", slog.Bool("corge", true)), slog.Group("", slog.String("grault", "garply")))
+	if err := h.Handle(context.Background(), r); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Entry{
+		{
+			Level:   slog.LevelWarn,
+			Message: "msg",
+			Attrs: map[string]slog.Value{
+				"foo":            slog.StringValue("bar"),
+				"baz.qux":        slog.IntValue(1),
+				"baz.quux.corge": slog.BoolValue(true),
+				"baz.grault":     slog.StringValue("garply"),
+			},
+		},
+	}
+	opt := cmp.Comparer(func(x, y slog.Value) bool { return x.Equal(y) })
+	if diff := cmp.Diff(sut.Entries(), want, opt); diff != "" {
+		t.Error(diff)
+	}
+
+	sut.Reset()
+	if got := len(sut.Entries()); got != 0 {
+		t.Errorf("len(Entries()) after Reset() = %d, want 0", got)
+	}
+}
+
+func TestRecorder_AssertLogged(t *testing.T) {
+	type args struct {
+		level slog.Level
+		msg   string
+		attrs []slog.Attr
+	}
+	type test struct {
+		args    args
+		wantErr bool
+	}
+	tests := map[string]test{
+		"happy-path": {
+			args: args{
+				level: slog.LevelInfo,
+				msg:   "msg",
+				attrs: []slog.Attr{slog.String("foo", "bar")},
+			},
+		},
+		"unhappy-path: level mismatch": {
+			args: args{
+				level: slog.LevelError,
+				msg:   "msg",
+			},
+			wantErr: true,
+		},
+		"unhappy-path: attr mismatch": {
+			args: args{
+				level: slog.LevelInfo,
+				msg:   "msg",
+				attrs: []slog.Attr{slog.String("foo", "baz")},
+			},
+			wantErr: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			sut := NewRecorder()
+			slog.New(sut).Info("msg", slog.String("foo", "bar"))
+			tb := &fakeTB{}
+			sut.AssertLogged(tb, tt.args.level, tt.args.msg, tt.args.attrs...)
+			if gotErr := len(tb.errors) > 0; gotErr != tt.wantErr {
+				t.Errorf("AssertLogged() failed = %v, want %v: %v", gotErr, tt.wantErr, tb.errors)
+			}
+		})
+	}
+}
+
+func TestRecorder_AssertNotLogged(t *testing.T) {
+	sut := NewRecorder()
+	slog.New(sut).Info("msg")
+	tb := &fakeTB{}
+	sut.AssertNotLogged(tb, slog.LevelDebug, "msg")
+	if len(tb.errors) != 0 {
+		t.Errorf("AssertNotLogged() failed unexpectedly: %v", tb.errors)
+	}
+	sut.AssertNotLogged(tb, slog.LevelInfo, "msg")
+	if len(tb.errors) != 1 {
+		t.Errorf("AssertNotLogged() must fail for a logged record")
+	}
+}
@@ -0,0 +1,51 @@
+package altnrslog
+
+import (
+	"log/slog"
+	"runtime"
+	"strings"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+// maxCodeAttrLength is the maximum length of the code attributes, the same as the limit of the APM Agent.
+const maxCodeAttrLength = 255
+
+// attrsFromPC converts the program counter of a record to the code attributes in the same format as
+// the code-level metrics reported by the APM Agent: code.function, code.namespace, code.filepath and code.lineno.
+// It returns nil if pc is zero or the function cannot be resolved.
+func attrsFromPC(pc uintptr) []slog.Attr {
+	if pc == 0 {
+		return nil
+	}
+	frames := runtime.CallersFrames([]uintptr{pc})
+	f, _ := frames.Next()
+	if f.Function == "" {
+		return nil
+	}
+
+	function := f.Function
+	namespace := ""
+	if ns := strings.LastIndex(f.Function, "."); ns >= 0 {
+		namespace = f.Function[:ns]
+		function = f.Function[ns+1:]
+	}
+	if function == "" || len(function) > maxCodeAttrLength {
+		return nil
+	}
+	if len(namespace) > maxCodeAttrLength && len(f.File) > maxCodeAttrLength {
+		return nil
+	}
+
+	attrs := []slog.Attr{
+		slog.String(newrelic.AttributeCodeFunction, function),
+		slog.Int(newrelic.AttributeCodeLineno, f.Line),
+	}
+	if len(namespace) <= maxCodeAttrLength {
+		attrs = append(attrs, slog.String(newrelic.AttributeCodeNamespace, namespace))
+	}
+	if len(f.File) <= maxCodeAttrLength {
+		attrs = append(attrs, slog.String(newrelic.AttributeCodeFilepath, f.File))
+	}
+	return attrs
+}
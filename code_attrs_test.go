@@ -0,0 +1,90 @@
+package altnrslog
+
+import (
+	"bytes"
+	"log/slog"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+type codeAttrsTestType struct{}
+
+func (codeAttrsTestType) pc() uintptr {
+	var pcs [1]uintptr
+	runtime.Callers(1, pcs[:])
+	return pcs[0]
+}
+
+func Test_attrsFromPC(t *testing.T) {
+	type want struct {
+		function  string
+		namespace string
+	}
+	type test struct {
+		args uintptr
+		want *want
+	}
+	tests := map[string]test{
+		"happy-path: method": {
+			args: codeAttrsTestType{}.pc(),
+			want: &want{
+				function:  "pc",
+				namespace: "github.com/miyamo2/altnrslog.codeAttrsTestType",
+			},
+		},
+		"unhappy-path: zero pc": {
+			args: 0,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := attrsFromPC(tt.args)
+			if tt.want == nil {
+				if got != nil {
+					t.Errorf("attrsFromPC() = %v, want nil", got)
+				}
+				return
+			}
+			m := map[string]slog.Value{}
+			for _, a := range got {
+				m[a.Key] = a.Value
+			}
+			if v := m[newrelic.AttributeCodeFunction].String(); v != tt.want.function {
+				t.Errorf("%s = %s, want %s", newrelic.AttributeCodeFunction, v, tt.want.function)
+			}
+			if v := m[newrelic.AttributeCodeNamespace].String(); v != tt.want.namespace {
+				t.Errorf("%s = %s, want %s", newrelic.AttributeCodeNamespace, v, tt.want.namespace)
+			}
+			if v := m[newrelic.AttributeCodeFilepath].String(); !strings.HasSuffix(v, "code_attrs_test.go") {
+				t.Errorf("%s = %s, want code_attrs_test.go", newrelic.AttributeCodeFilepath, v)
+			}
+			if v := m[newrelic.AttributeCodeLineno].Int64(); v <= 0 {
+				t.Errorf("%s = %d, want positive", newrelic.AttributeCodeLineno, v)
+			}
+		})
+	}
+}
+
+func TestTransactionalHandler_Handle_WithCodeAttributes(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(NewTransactionalHandler(nil, nil, testHelper_BufferOption(buf), WithCodeAttributes()))
+	logger.Info("msg")
+
+	lines := testHelper_JSONLines(t, buf)
+	if len(lines) != 1 {
+		t.Fatalf("logged %d records, want 1", len(lines))
+	}
+	l := lines[0]
+	if l[newrelic.AttributeCodeFunction] != "TestTransactionalHandler_Handle_WithCodeAttributes" {
+		t.Errorf("%s = %v, want %v", newrelic.AttributeCodeFunction, l[newrelic.AttributeCodeFunction], "TestTransactionalHandler_Handle_WithCodeAttributes")
+	}
+	if l[newrelic.AttributeCodeNamespace] != "github.com/miyamo2/altnrslog" {
+		t.Errorf("%s = %v, want %v", newrelic.AttributeCodeNamespace, l[newrelic.AttributeCodeNamespace], "github.com/miyamo2/altnrslog")
+	}
+	if _, ok := l[slog.SourceKey]; ok {
+		t.Errorf("%s must not be added", slog.SourceKey)
+	}
+}
@@ -0,0 +1,220 @@
+package altnrslog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrInvalidConfig is returned if a [Config] has an invalid value.
+var ErrInvalidConfig = errors.New("invalid config")
+
+// Config is the configuration of [TransactionalHandler] loaded from a YAML or JSON file.
+//
+//	level: debug
+//	format: json
+//	add_source: true
+//	writers:
+//	  - type: stdout
+//	  - type: file
+//	    path: /var/log/app/app.log
+//	    max_size: 104857600
+//	    rotation_interval: 24h
+//	    max_backups: 7
+//	    compress: true
+//	redact_keys: [password, authorization]
+//	sampling:
+//	  rate: 0.1
+//	  level: info
+type Config struct {
+	// Level is the log level, such as debug, INFO or WARN+2. if empty, INFO is used.
+	Level string `json:"level" yaml:"level"`
+	// Format is the output format, json or text. if empty, text is used.
+	Format string `json:"format" yaml:"format"`
+	// AddSource specifies whether to add the source location. See: [slog.HandlerOptions.AddSource]
+	AddSource bool `json:"add_source" yaml:"add_source"`
+	// Writers are the destinations the records are written to. if empty, [os.Stdout] is used.
+	Writers []WriterConfig `json:"writers" yaml:"writers"`
+	// RedactKeys are the keys of the attributes to be redacted. See: [WithRedactKeys]
+	RedactKeys []string `json:"redact_keys" yaml:"redact_keys"`
+	// Sampling is the sampling of the records. if nil, all the records are handled. See: [WithSampling]
+	Sampling *SamplingConfig `json:"sampling" yaml:"sampling"`
+}
+
+// WriterConfig is the configuration of a destination the records are written to.
+type WriterConfig struct {
+	// Type is the type of the destination, stdout, stderr or file.
+	Type string `json:"type" yaml:"type"`
+	// Path is the name of the file. It is required if Type is file.
+	Path string `json:"path" yaml:"path"`
+	// MaxSize is the maximum size of the file in bytes. See: [WithMaxSize]
+	MaxSize int64 `json:"max_size" yaml:"max_size"`
+	// RotationInterval is the interval of the rotation, such as 24h. See: [WithRotationInterval]
+	RotationInterval string `json:"rotation_interval" yaml:"rotation_interval"`
+	// MaxBackups is the maximum number of the backups. See: [WithMaxBackups]
+	MaxBackups int `json:"max_backups" yaml:"max_backups"`
+	// Compress specifies whether to compress the backups. See: [WithCompress]
+	Compress bool `json:"compress" yaml:"compress"`
+}
+
+// SamplingConfig is the configuration of the sampling of the records.
+type SamplingConfig struct {
+	// Rate is the fraction of the records to be handled, between 0 and 1.
+	Rate float64 `json:"rate" yaml:"rate"`
+	// Level is the highest level of the records to be sampled. if empty, INFO is used.
+	Level string `json:"level" yaml:"level"`
+}
+
+// LoadConfig loads a [Config] from the file named path, and validates it.
+// The file is decoded as YAML if its extension is .yaml or .yml, otherwise as JSON.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseConfig(data, filepath.Ext(path))
+}
+
+// parseConfig decodes data as YAML or JSON depending on ext, and validates it.
+func parseConfig(data []byte, ext string) (*Config, error) {
+	c := &Config{}
+	var err error
+	switch strings.ToLower(ext) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, c)
+	default:
+		err = json.Unmarshal(data, c)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Validate reports all the invalid values of the config as the errors wrapping [ErrInvalidConfig].
+func (c *Config) Validate() error {
+	var errs []error
+	invalid := func(field, v string, err error) {
+		errs = append(errs, fmt.Errorf("%w: %s=%q: %v", ErrInvalidConfig, field, v, err))
+	}
+	if _, err := c.level(); err != nil {
+		invalid("level", c.Level, err)
+	}
+	switch strings.ToLower(c.Format) {
+	case "", "json", "text":
+	default:
+		invalid("format", c.Format, errors.New("must be json or text"))
+	}
+	for i, w := range c.Writers {
+		field := fmt.Sprintf("writers[%d]", i)
+		switch strings.ToLower(w.Type) {
+		case "stdout", "stderr":
+		case "file":
+			if w.Path == "" {
+				invalid(field+".path", w.Path, errors.New("must not be empty"))
+			}
+			if _, err := w.rotationInterval(); err != nil {
+				invalid(field+".rotation_interval", w.RotationInterval, err)
+			}
+		default:
+			invalid(field+".type", w.Type, errors.New("must be stdout, stderr or file"))
+		}
+	}
+	if c.Sampling != nil {
+		if c.Sampling.Rate < 0 || c.Sampling.Rate > 1 {
+			invalid("sampling.rate", fmt.Sprint(c.Sampling.Rate), errors.New("must be between 0 and 1"))
+		}
+		if _, err := c.Sampling.level(); err != nil {
+			invalid("sampling.level", c.Sampling.Level, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// level returns the parsed log level.
+func (c *Config) level() (slog.Level, error) {
+	return parseLevel(c.Level, slog.LevelInfo)
+}
+
+// filters returns the filters specified by the config.
+func (c *Config) filters() *filters {
+	f := &filters{redactKeys: redactKeySet(c.RedactKeys)}
+	if c.Sampling != nil {
+		level, _ := c.Sampling.level()
+		f.sampling = newSampling(c.Sampling.Rate, level)
+	}
+	return f
+}
+
+// openWriters opens the destinations specified by the config.
+// The returned closers must be closed by the caller.
+func (c *Config) openWriters() (io.Writer, []io.Closer, error) {
+	if len(c.Writers) == 0 {
+		return os.Stdout, nil, nil
+	}
+	var (
+		writers []io.Writer
+		closers []io.Closer
+	)
+	for _, wc := range c.Writers {
+		switch strings.ToLower(wc.Type) {
+		case "stdout":
+			writers = append(writers, os.Stdout)
+		case "stderr":
+			writers = append(writers, os.Stderr)
+		case "file":
+			interval, _ := wc.rotationInterval()
+			w, err := NewRotatingFileWriter(wc.Path,
+				WithMaxSize(wc.MaxSize),
+				WithRotationInterval(interval),
+				WithMaxBackups(wc.MaxBackups),
+				WithCompress(wc.Compress))
+			if err != nil {
+				for _, c := range closers {
+					c.Close()
+				}
+				return nil, nil, err
+			}
+			writers = append(writers, w)
+			closers = append(closers, w)
+		}
+	}
+	if len(writers) == 1 {
+		return writers[0], closers, nil
+	}
+	return io.MultiWriter(writers...), closers, nil
+}
+
+// rotationInterval returns the parsed rotation interval.
+func (w WriterConfig) rotationInterval() (time.Duration, error) {
+	if w.RotationInterval == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(w.RotationInterval)
+}
+
+// level returns the parsed highest level of the records to be sampled.
+func (s *SamplingConfig) level() (slog.Level, error) {
+	return parseLevel(s.Level, slog.LevelInfo)
+}
+
+// parseLevel parses s as a [slog.Level]. if s is empty, def is returned.
+func parseLevel(s string, def slog.Level) (slog.Level, error) {
+	if s == "" {
+		return def, nil
+	}
+	var level slog.Level
+	err := level.UnmarshalText([]byte(s))
+	return level, err
+}
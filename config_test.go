@@ -0,0 +1,142 @@
+package altnrslog
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLoadConfig(t *testing.T) {
+	want := &Config{
+		Level:     "debug",
+		Format:    "json",
+		AddSource: true,
+		Writers: []WriterConfig{
+			{Type: "stdout"},
+			{Type: "file", Path: "app.log", MaxSize: 1024, RotationInterval: "24h", MaxBackups: 7, Compress: true},
+		},
+		RedactKeys: []string{"password", "authorization"},
+		Sampling:   &SamplingConfig{Rate: 0.1, Level: "info"},
+	}
+	type test struct {
+		filename string
+		data     string
+	}
+	tests := map[string]test{
+		"happy-path: yaml": {
+			filename: "altnrslog.yaml",
+			data: `level: debug
+format: json
+add_source: true
+writers:
+  - type: stdout
+  - type: file
+    path: app.log
+    max_size: 1024
+    rotation_interval: 24h
+    max_backups: 7
+    compress: true
+redact_keys: [password, authorization]
+sampling:
+  rate: 0.1
+  level: info
+`,
+		},
+		"happy-path: json": {
+			filename: "altnrslog.json",
+			data: `{
+  "level": "debug",
+  "format": "json",
+  "add_source": true,
+  "writers": [
+    {"type": "stdout"},
+    {"type": "file", "path": "app.log", "max_size": 1024, "rotation_interval": "24h", "max_backups": 7, "compress": true}
+  ],
+  "redact_keys": ["password", "authorization"],
+  "sampling": {"rate": 0.1, "level": "info"}
+}`,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := testHelper_WriteFile(t, filepath.Join(t.TempDir(), tt.filename), tt.data)
+			got, err := LoadConfig(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(got, want); diff != "" {
+				t.Errorf("(-got, +want)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	type test struct {
+		args    Config
+		wantErr bool
+	}
+	tests := map[string]test{
+		"happy-path: zero": {
+			args: Config{},
+		},
+		"unhappy-path: level": {
+			args:    Config{Level: "verbose"},
+			wantErr: true,
+		},
+		"unhappy-path: format": {
+			args:    Config{Format: "yaml"},
+			wantErr: true,
+		},
+		"unhappy-path: writer type": {
+			args:    Config{Writers: []WriterConfig{{Type: "syslog"}}},
+			wantErr: true,
+		},
+		"unhappy-path: file without path": {
+			args:    Config{Writers: []WriterConfig{{Type: "file"}}},
+			wantErr: true,
+		},
+		"unhappy-path: rotation interval": {
+			args:    Config{Writers: []WriterConfig{{Type: "file", Path: "app.log", RotationInterval: "daily"}}},
+			wantErr: true,
+		},
+		"unhappy-path: sampling": {
+			args:    Config{Sampling: &SamplingConfig{Rate: 2, Level: "loud"}},
+			wantErr: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tt.args.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidConfig) {
+				t.Errorf("Validate() error = %v, want %v", err, ErrInvalidConfig)
+			}
+		})
+	}
+}
+
+func TestConfig_filters(t *testing.T) {
+	c := &Config{RedactKeys: []string{"password"}, Sampling: &SamplingConfig{Rate: 0.5}}
+	got := c.filters()
+	if _, ok := got.redactKeys["password"]; !ok || len(got.redactKeys) != 1 {
+		t.Errorf("redactKeys = %v, want [password]", got.redactKeys)
+	}
+	if got.sampling == nil || *got.sampling != (sampling{rate: 0.5, level: slog.LevelInfo}) {
+		t.Errorf("sampling = %+v, want rate 0.5 at or below INFO", got.sampling)
+	}
+}
+
+func testHelper_WriteFile(t *testing.T, name, data string) string {
+	t.Helper()
+	if err := os.WriteFile(name, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
@@ -0,0 +1,214 @@
+package altnrslog
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultPollInterval is the interval at which [ConfigWatcher] checks the file for changes by default.
+const defaultPollInterval = 5 * time.Second
+
+// ConfigWatcher loads a [Config] from a file and reloads it when the file changes.
+//
+// The level, the redaction and the sampling are swapped atomically in the live handlers created with
+// [ConfigWatcher.HandlerOptions]. The format and the writers take effect only when the watcher is created.
+// The attributes added by [slog.Logger.With] are redacted by the new keys as well,
+// as the inner handler of each live handler is rebuilt once after the swap.
+//
+// If a reloaded config is invalid, the current config is kept and the handlers keep logging with it.
+type ConfigWatcher struct {
+	path    string
+	props   *ConfigWatcherProperties
+	filters *liveFilters
+	config  atomic.Pointer[Config]
+	writer  io.Writer
+	closers []io.Closer
+
+	modTime time.Time
+	size    int64
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// ConfigWatcherProperties is an options for creating a new [ConfigWatcher].
+type ConfigWatcherProperties struct {
+	pollInterval       time.Duration
+	reloadErrorHandler func(error)
+	levelVar           *slog.LevelVar
+}
+
+// ConfigWatcherOption is a functional option for creating a new [ConfigWatcher].
+type ConfigWatcherOption func(*ConfigWatcherProperties)
+
+// WithPollInterval specifies the interval at which the file is checked for changes.
+// if not specified, the default is 5 seconds. if zero or negative, the file is not checked,
+// and the config is reloaded only by [ConfigWatcher.Reload].
+func WithPollInterval(interval time.Duration) ConfigWatcherOption {
+	return func(p *ConfigWatcherProperties) {
+		p.pollInterval = interval
+	}
+}
+
+// WithReloadErrorHandler specifies the function called with the error of a reload triggered by a change of the file,
+// such as an invalid config. if not specified, the errors are discarded.
+func WithReloadErrorHandler(fn func(error)) ConfigWatcherOption {
+	return func(p *ConfigWatcherProperties) {
+		p.reloadErrorHandler = fn
+	}
+}
+
+// WithLevelVar specifies the [slog.LevelVar] the level of the config is set to,
+// so that it can be shared with other loggers. if not specified, a new one is used.
+func WithLevelVar(v *slog.LevelVar) ConfigWatcherOption {
+	return func(p *ConfigWatcherProperties) {
+		p.levelVar = v
+	}
+}
+
+// buildConfigWatcherProperties creates a new ConfigWatcherProperties with the given options.
+func buildConfigWatcherProperties(options []ConfigWatcherOption) *ConfigWatcherProperties {
+	p := &ConfigWatcherProperties{pollInterval: defaultPollInterval}
+	for _, o := range options {
+		o(p)
+	}
+	if p.levelVar == nil {
+		p.levelVar = &slog.LevelVar{}
+	}
+	return p
+}
+
+// WatchConfig is constructor for [ConfigWatcher].
+// It loads the config from the file named path, opens the writers and starts watching the file.
+//
+// The watcher must be closed by [ConfigWatcher.Close] after the handlers are no longer used.
+func WatchConfig(path string, options ...ConfigWatcherOption) (*ConfigWatcher, error) {
+	w := &ConfigWatcher{
+		path:    path,
+		props:   buildConfigWatcherProperties(options),
+		filters: &liveFilters{},
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	c, err := LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	if w.writer, w.closers, err = c.openWriters(); err != nil {
+		return nil, err
+	}
+	w.modTime, w.size = fi.ModTime(), fi.Size()
+	w.apply(c)
+
+	if w.props.pollInterval <= 0 {
+		close(w.done)
+		return w, nil
+	}
+	go w.poll()
+	return w, nil
+}
+
+// HandlerOptions returns the [HandlerOption] specified by the config, to be passed to
+// [NewHandlerFactory] or [NewTransactionalHandler].
+func (w *ConfigWatcher) HandlerOptions() []HandlerOption {
+	c := w.Config()
+	return []HandlerOption{
+		WithInnerWriter(w.writer),
+		WithSlogHandlerSpecify(strings.EqualFold(c.Format, "json"), &slog.HandlerOptions{AddSource: c.AddSource}),
+		WithLeveler(w.props.levelVar),
+		withLiveFilters(w.filters),
+	}
+}
+
+// Config returns the current config.
+// The returned config must not be modified.
+func (w *ConfigWatcher) Config() *Config {
+	return w.config.Load()
+}
+
+// Level returns the [slog.LevelVar] the level of the config is set to.
+func (w *ConfigWatcher) Level() *slog.LevelVar {
+	return w.props.levelVar
+}
+
+// Reload loads the config from the file and swaps the level, the redaction and the sampling.
+// If the config is invalid, the current config is kept and the error is returned.
+func (w *ConfigWatcher) Reload() error {
+	c, err := LoadConfig(w.path)
+	if err != nil {
+		return err
+	}
+	w.apply(c)
+	return nil
+}
+
+// Close stops watching the file and closes the writers.
+func (w *ConfigWatcher) Close() error {
+	var errs []error
+	w.closeOnce.Do(func() {
+		close(w.stop)
+		<-w.done
+		for _, c := range w.closers {
+			errs = append(errs, c.Close())
+		}
+	})
+	return errors.Join(errs...)
+}
+
+// apply swaps the settings of the handlers for the ones of c.
+func (w *ConfigWatcher) apply(c *Config) {
+	level, _ := c.level()
+	w.props.levelVar.Set(level)
+	w.filters.store(c.filters())
+	w.config.Store(c)
+}
+
+// poll checks the file for changes at the interval until the watcher is closed.
+func (w *ConfigWatcher) poll() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.props.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			if err := w.reloadIfChanged(); err != nil && w.props.reloadErrorHandler != nil {
+				w.props.reloadErrorHandler(err)
+			}
+		}
+	}
+}
+
+// reloadIfChanged reloads the config if the modification time or the size of the file has changed.
+// The error of an invalid config is returned once per change.
+func (w *ConfigWatcher) reloadIfChanged() error {
+	fi, err := os.Stat(w.path)
+	if err != nil {
+		return err
+	}
+	changed := !fi.ModTime().Equal(w.modTime) || fi.Size() != w.size
+	w.modTime, w.size = fi.ModTime(), fi.Size()
+	if !changed {
+		return nil
+	}
+	return w.Reload()
+}
+
+// withLiveFilters specifies the filters swapped while the handlers are live.
+func withLiveFilters(l *liveFilters) HandlerOption {
+	return func(p *Properties) {
+		p.liveFilters = l
+	}
+}
@@ -0,0 +1,189 @@
+package altnrslog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestConfigWatcher_Reload(t *testing.T) {
+	path := testHelper_WriteFile(t, filepath.Join(t.TempDir(), "altnrslog.yaml"), "level: warn\nredact_keys: [password]\n")
+	w, err := WatchConfig(path, WithPollInterval(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	buf := &bytes.Buffer{}
+	options := append(w.HandlerOptions(), WithInnerWriter(buf), WithSlogHandlerSpecify(true, nil))
+	logger := slog.New(NewTransactionalHandler(nil, nil, options...))
+	ctx := context.Background()
+
+	logger.InfoContext(ctx, "before reload")
+	logger.WarnContext(ctx, "redacted", slog.String("password", "foo"))
+
+	testHelper_WriteFile(t, path, "level: debug\n")
+	if err := w.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	logger.DebugContext(ctx, "after reload", slog.String("password", "foo"))
+
+	testHelper_WriteFile(t, path, "level: verbose\n")
+	if err := w.Reload(); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Reload() error = %v, want %v", err, ErrInvalidConfig)
+	}
+	logger.DebugContext(ctx, "after invalid reload")
+
+	lines := testHelper_JSONLines(t, buf)
+	if len(lines) != 3 {
+		t.Fatalf("logged %d records, want 3: %v", len(lines), lines)
+	}
+	type want struct {
+		msg      string
+		password string
+	}
+	for i, want := range []want{
+		{msg: "redacted", password: RedactedValue},
+		{msg: "after reload", password: "foo"},
+		{msg: "after invalid reload"},
+	} {
+		if lines[i][slog.MessageKey] != want.msg {
+			t.Errorf("lines[%d].msg = %v, want %v", i, lines[i][slog.MessageKey], want.msg)
+		}
+		if want.password != "" && lines[i]["password"] != want.password {
+			t.Errorf("lines[%d].password = %v, want %v", i, lines[i]["password"], want.password)
+		}
+	}
+	if got := w.Config().Level; got != "debug" {
+		t.Errorf("Config().Level = %v, want %v", got, "debug")
+	}
+}
+
+func TestConfigWatcher_Reload_WithAttrs(t *testing.T) {
+	path := testHelper_WriteFile(t, filepath.Join(t.TempDir(), "altnrslog.yaml"), "redact_keys: [password]\n")
+	w, err := WatchConfig(path, WithPollInterval(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	buf := &bytes.Buffer{}
+	options := append(w.HandlerOptions(), WithInnerWriter(buf), WithSlogHandlerSpecify(true, nil))
+	logger := slog.New(NewTransactionalHandler(nil, nil, options...)).
+		With(slog.String("password", "foo"), slog.String("token", "bar")).
+		WithGroup("request")
+	ctx := context.Background()
+
+	logger.InfoContext(ctx, "before reload")
+
+	testHelper_WriteFile(t, path, "redact_keys: [token]\n")
+	if err := w.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	logger.InfoContext(ctx, "after reload")
+	logger.With(slog.String("token", "baz")).InfoContext(ctx, "derived after reload")
+
+	lines := testHelper_JSONLines(t, buf)
+	if len(lines) != 3 {
+		t.Fatalf("logged %d records, want 3: %v", len(lines), lines)
+	}
+	type want struct {
+		password     string
+		token        string
+		requestToken string
+	}
+	for i, want := range []want{
+		{password: RedactedValue, token: "bar"},
+		{password: "foo", token: RedactedValue},
+		{password: "foo", token: RedactedValue, requestToken: RedactedValue},
+	} {
+		if lines[i]["password"] != want.password {
+			t.Errorf("lines[%d].password = %v, want %v", i, lines[i]["password"], want.password)
+		}
+		if lines[i]["token"] != want.token {
+			t.Errorf("lines[%d].token = %v, want %v", i, lines[i]["token"], want.token)
+		}
+		if want.requestToken == "" {
+			continue
+		}
+		if request, _ := lines[i]["request"].(map[string]any); request["token"] != want.requestToken {
+			t.Errorf("lines[%d].request.token = %v, want %v", i, request["token"], want.requestToken)
+		}
+	}
+}
+
+func TestConfigWatcher_poll(t *testing.T) {
+	path := testHelper_WriteFile(t, filepath.Join(t.TempDir(), "altnrslog.json"), `{"level": "info"}`)
+	errs := make(chan error, 1)
+	w, err := WatchConfig(path,
+		WithPollInterval(10*time.Millisecond),
+		WithReloadErrorHandler(func(err error) { errs <- err }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	testHelper_WriteFile(t, path, `{"level": "error"}`)
+	testHelper_Eventually(t, func() bool { return w.Level().Level() == slog.LevelError })
+
+	testHelper_WriteFile(t, path, `{"level": "error", "format": "yaml"}`)
+	select {
+	case err := <-errs:
+		if !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("reload error = %v, want %v", err, ErrInvalidConfig)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("reload error not reported")
+	}
+	if got := w.Level().Level(); got != slog.LevelError {
+		t.Errorf("Level() = %v, want %v", got, slog.LevelError)
+	}
+}
+
+func TestWatchConfig_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := WatchConfig(filepath.Join(dir, "missing.yaml")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("WatchConfig() error = %v, want %v", err, os.ErrNotExist)
+	}
+	path := testHelper_WriteFile(t, filepath.Join(dir, "altnrslog.yaml"), "level: [")
+	if _, err := WatchConfig(path); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("WatchConfig() error = %v, want %v", err, ErrInvalidConfig)
+	}
+}
+
+func TestConfigWatcher_Close(t *testing.T) {
+	dir := t.TempDir()
+	logFile := filepath.Join(dir, "app.log")
+	path := testHelper_WriteFile(t, filepath.Join(dir, "altnrslog.yaml"), "writers:\n  - type: file\n    path: "+logFile+"\n")
+	w, err := WatchConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := slog.New(NewTransactionalHandler(nil, nil, w.HandlerOptions()...))
+	logger.Info("foo")
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Errorf("second Close() error = %v", err)
+	}
+	if got := testHelper_ReadFile(t, logFile); got == "" {
+		t.Error("record not written to the file")
+	}
+}
+
+func testHelper_Eventually(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not satisfied in time")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
 )
 
 type loggerKey struct{}
@@ -38,3 +40,31 @@ func StoreToContext(ctx context.Context, logger *slog.Logger) (context.Context,
 	}
 	return context.WithValue(ctx, loggerKey{}, logger), nil
 }
+
+// ContextWithLogger creates [*slog.Logger] with [*TransactionalHandler] by factory for the [newrelic.Transaction] in ctx,
+// and stores it in ctx.
+//
+// It is the shared core of the middlewares for net/http and the web frameworks.
+func ContextWithLogger(ctx context.Context, factory *HandlerFactory) (context.Context, *slog.Logger) {
+	return ContextWithTransactionLogger(ctx, factory, newrelic.FromContext(ctx))
+}
+
+// ContextWithTransactionLogger creates [*slog.Logger] with [*TransactionalHandler] by factory for tx,
+// and stores it in ctx.
+//
+// It is for the integrations that place the [newrelic.Transaction] somewhere other than ctx, such as nrgin.
+func ContextWithTransactionLogger(ctx context.Context, factory *HandlerFactory, tx *newrelic.Transaction) (context.Context, *slog.Logger) {
+	logger := slog.New(factory.New(tx))
+	// StoreToContext never fails for the logger with TransactionalHandler.
+	ctx, _ = StoreToContext(ctx, logger)
+	return ctx, logger
+}
+
+// LoggerOrDefault returns [*slog.Logger] with [*TransactionalHandler] stored in ctx.
+// If it does not exist, [slog.Default] is returned.
+func LoggerOrDefault(ctx context.Context) *slog.Logger {
+	if logger, err := FromContext(ctx); err == nil {
+		return logger
+	}
+	return slog.Default()
+}
@@ -3,9 +3,12 @@ package altnrslog
 import (
 	"context"
 	"errors"
+	"io"
 	"log/slog"
 	"reflect"
 	"testing"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
 )
 
 func TestFromContext(t *testing.T) {
@@ -118,3 +121,52 @@ func TestStoreToContext(t *testing.T) {
 		})
 	}
 }
+
+func TestContextWithLogger(t *testing.T) {
+	app := testHelper_Application(t)
+	tx := app.StartTransaction("test")
+	defer tx.End()
+	factory := NewHandlerFactory(app, WithInnerWriter(io.Discard))
+
+	ctx, logger := ContextWithLogger(newrelic.NewContext(context.Background(), tx), factory)
+	stored, err := FromContext(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored != logger {
+		t.Errorf("FromContext() = %p, want %p", stored, logger)
+	}
+	if got := logger.Handler().(*TransactionalHandler).Transaction(); got != tx {
+		t.Errorf("Transaction() = %p, want %p", got, tx)
+	}
+}
+
+func TestContextWithTransactionLogger(t *testing.T) {
+	app := testHelper_Application(t)
+	tx := app.StartTransaction("test")
+	defer tx.End()
+	factory := NewHandlerFactory(app, WithInnerWriter(io.Discard))
+
+	ctx, logger := ContextWithTransactionLogger(context.Background(), factory, tx)
+	stored, err := FromContext(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored != logger {
+		t.Errorf("FromContext() = %p, want %p", stored, logger)
+	}
+	if got := logger.Handler().(*TransactionalHandler).Transaction(); got != tx {
+		t.Errorf("Transaction() = %p, want %p", got, tx)
+	}
+}
+
+func TestLoggerOrDefault(t *testing.T) {
+	txLogger := slog.New(&TransactionalHandler{})
+	ctx := context.WithValue(context.Background(), loggerKey{}, txLogger)
+	if got := LoggerOrDefault(ctx); got != txLogger {
+		t.Errorf("LoggerOrDefault() = %p, want %p", got, txLogger)
+	}
+	if got := LoggerOrDefault(context.Background()); got != slog.Default() {
+		t.Errorf("LoggerOrDefault() = %p, want %p", got, slog.Default())
+	}
+}
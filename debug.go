@@ -0,0 +1,57 @@
+package altnrslog
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+)
+
+// EnableDebug escalates the level of the transaction of [*slog.Logger] stored in ctx to [slog.LevelDebug],
+// so that only the transaction is logged in detail, such as the requests of a customer under investigation.
+//
+// The escalation applies to all the handlers bound to the transaction, including the ones derived by
+// [slog.Logger.With], [Go] and [StartSegment], and bypasses the level of the inner handler.
+// If ctx has no [*slog.Logger] with [*TransactionalHandler], [ErrNotStored] is returned.
+func EnableDebug(ctx context.Context) error {
+	logger, err := FromContext(ctx)
+	if err != nil {
+		return err
+	}
+	h := logger.Handler().(*TransactionalHandler)
+	if h.state != nil {
+		h.state.debug.Store(true)
+	}
+	return nil
+}
+
+// DebugEnabled reports whether the level of the transaction of [*slog.Logger] stored in ctx is escalated by [EnableDebug].
+func DebugEnabled(ctx context.Context) bool {
+	logger, err := FromContext(ctx)
+	if err != nil {
+		return false
+	}
+	return logger.Handler().(*TransactionalHandler).debugEnabled()
+}
+
+// DebugMiddleware returns the middleware that escalates the level of the transactions of the requests
+// matching predicate by [EnableDebug], such as the ones with a header, a user ID or a trace ID.
+//
+// It must be placed after the middleware storing [*slog.Logger] by [StoreToContext].
+func DebugMiddleware(next http.Handler, predicate func(r *http.Request) bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if predicate(r) {
+			EnableDebug(r.Context())
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// debugEnabled reports whether the level of the transaction is escalated by [EnableDebug].
+func (h *TransactionalHandler) debugEnabled() bool {
+	return h.state != nil && h.state.debug.Load()
+}
+
+// escalated reports whether a record at level is enabled by the escalation of [EnableDebug].
+func (h *TransactionalHandler) escalated(level slog.Level) bool {
+	return level >= slog.LevelDebug && h.debugEnabled()
+}
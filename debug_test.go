@@ -0,0 +1,110 @@
+package altnrslog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestEnableDebug(t *testing.T) {
+	buf := &bytes.Buffer{}
+	ctx := testHelper_LoggerContext(t, buf)
+	logger, _ := FromContext(ctx)
+	derived := logger.With("user", "foo")
+
+	logger.DebugContext(ctx, "before")
+	if DebugEnabled(ctx) {
+		t.Error("DebugEnabled() = true, want false")
+	}
+	if err := EnableDebug(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !DebugEnabled(ctx) {
+		t.Error("DebugEnabled() = false, want true")
+	}
+	logger.DebugContext(ctx, "after")
+	derived.DebugContext(ctx, "derived")
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	Go(ctx, func(ctx context.Context) {
+		defer wg.Done()
+		logger, _ := FromContext(ctx)
+		logger.DebugContext(ctx, "goroutine")
+	})
+	wg.Wait()
+
+	var got []any
+	for _, line := range testHelper_JSONLines(t, buf) {
+		got = append(got, line[slog.MessageKey])
+	}
+	want := []any{"after", "derived", "goroutine"}
+	if len(got) != len(want) {
+		t.Fatalf("logged %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("logged %v, want %v", got, want)
+		}
+	}
+}
+
+func TestEnableDebug_OtherTransaction(t *testing.T) {
+	buf := &bytes.Buffer{}
+	ctx := testHelper_LoggerContext(t, buf)
+	other := testHelper_LoggerContext(t, buf)
+	if err := EnableDebug(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if DebugEnabled(other) {
+		t.Error("DebugEnabled() of other transaction = true, want false")
+	}
+}
+
+func TestEnableDebug_NotStored(t *testing.T) {
+	if err := EnableDebug(context.Background()); !errors.Is(err, ErrNotStored) {
+		t.Errorf("EnableDebug() error = %v, want %v", err, ErrNotStored)
+	}
+	if DebugEnabled(context.Background()) {
+		t.Error("DebugEnabled() = true, want false")
+	}
+}
+
+func TestDebugMiddleware(t *testing.T) {
+	type test struct {
+		header string
+		want   bool
+	}
+	tests := map[string]test{
+		"happy-path: matched": {
+			header: "1",
+			want:   true,
+		},
+		"happy-path: not matched": {
+			want: false,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var got bool
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = DebugEnabled(r.Context())
+			})
+			sut := DebugMiddleware(next, func(r *http.Request) bool {
+				return r.Header.Get("X-Debug") == "1"
+			})
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("X-Debug", tt.header)
+			req = req.WithContext(testHelper_LoggerContext(t, &bytes.Buffer{}))
+			sut.ServeHTTP(httptest.NewRecorder(), req)
+			if got != tt.want {
+				t.Errorf("DebugEnabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
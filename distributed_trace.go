@@ -0,0 +1,43 @@
+package altnrslog
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+// StartTransactionFromHeaders starts a new [newrelic.Transaction] that continues the distributed trace
+// carried by the inbound headers, such as traceparent, tracestate and newrelic,
+// and returns [context.Context] storing the transaction and [*slog.Logger] with [*TransactionalHandler] bound to it.
+//
+// It is intended for entry points that are not instrumented by New Relic's integrations, such as message consumers.
+// The caller is responsible for ending the returned transaction.
+func StartTransactionFromHeaders(ctx context.Context, app *newrelic.Application, name string, transport newrelic.TransportType, hdrs http.Header, options ...HandlerOption) (context.Context, *slog.Logger, *newrelic.Transaction) {
+	tx := app.StartTransaction(name)
+	tx.AcceptDistributedTraceHeaders(transport, hdrs)
+	ctx = newrelic.NewContext(ctx, tx)
+	logger := slog.New(NewTransactionalHandler(app, tx, options...))
+	// StoreToContext never fails for the logger with TransactionalHandler.
+	ctx, _ = StoreToContext(ctx, logger)
+	return ctx, logger, tx
+}
+
+// HeadersFromMap converts the headers of a message, such as SQS message attributes, to [http.Header].
+func HeadersFromMap(m map[string]string) http.Header {
+	hdrs := make(http.Header, len(m))
+	for k, v := range m {
+		hdrs.Set(k, v)
+	}
+	return hdrs
+}
+
+// HeadersFromBytesMap converts the headers of a message with binary values, such as Kafka record headers, to [http.Header].
+func HeadersFromBytesMap(m map[string][]byte) http.Header {
+	hdrs := make(http.Header, len(m))
+	for k, v := range m {
+		hdrs.Set(k, string(v))
+	}
+	return hdrs
+}
@@ -0,0 +1,62 @@
+package altnrslog
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+func TestStartTransactionFromHeaders(t *testing.T) {
+	app := testHelper_Application(t)
+	hdrs := HeadersFromMap(map[string]string{
+		"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	})
+
+	ctx, logger, tx := StartTransactionFromHeaders(context.Background(), app, "consume", newrelic.TransportQueue, hdrs)
+	defer tx.End()
+
+	if got := newrelic.FromContext(ctx); got != tx {
+		t.Errorf("newrelic.FromContext() = %p, want %p", got, tx)
+	}
+	stored, err := FromContext(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored != logger {
+		t.Errorf("FromContext() = %p, want %p", stored, logger)
+	}
+	if got := logger.Handler().(*TransactionalHandler).Transaction(); got != tx {
+		t.Errorf("Transaction() = %p, want %p", got, tx)
+	}
+}
+
+func TestHeadersFromMap(t *testing.T) {
+	got := HeadersFromMap(map[string]string{
+		"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"newrelic":    "payload",
+	})
+	want := http.Header{
+		"Traceparent": []string{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		"Newrelic":    []string{"payload"},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestHeadersFromBytesMap(t *testing.T) {
+	got := HeadersFromBytesMap(map[string][]byte{
+		"traceparent": []byte("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"),
+		"tracestate":  []byte("foo=bar"),
+	})
+	want := http.Header{
+		"Traceparent": []string{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		"Tracestate":  []string{"foo=bar"},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,118 @@
+package altnrslog
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	// EnvLevel is the environment variable specifying the log level, such as debug, INFO or WARN+2.
+	EnvLevel = "ALTNRSLOG_LEVEL"
+	// EnvFormat is the environment variable specifying the output format, json or text.
+	EnvFormat = "ALTNRSLOG_FORMAT"
+	// EnvAddSource is the environment variable specifying whether to add the source location, such as true or 0.
+	EnvAddSource = "ALTNRSLOG_ADD_SOURCE"
+	// EnvRedactKeys is the environment variable specifying the comma-separated keys of the attributes to be redacted.
+	EnvRedactKeys = "ALTNRSLOG_REDACT_KEYS"
+)
+
+// ErrInvalidEnv is returned by [OptionsFromEnv] if an environment variable has an invalid value.
+var ErrInvalidEnv = errors.New("invalid environment variable")
+
+// OptionsFromEnv returns the [HandlerOption] specified by the environment variables
+// [EnvLevel], [EnvFormat], [EnvAddSource] and [EnvRedactKeys].
+//
+// The unset variables are ignored, so the options are intended to be appended to the ones specified in code
+// to override them. The level, the format and the source location apply to the inner handler
+// created by this package, keeping the other [slog.HandlerOptions] specified by [WithSlogHandlerSpecify].
+//
+// If any variable has an invalid value, the options of the valid ones are returned
+// along with the errors wrapping [ErrInvalidEnv].
+func OptionsFromEnv() ([]HandlerOption, error) {
+	var (
+		options []HandlerOption
+		errs    []error
+	)
+	if v, ok := os.LookupEnv(EnvLevel); ok {
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(v)); err != nil {
+			errs = append(errs, invalidEnvError(EnvLevel, v, err))
+		} else {
+			options = append(options, withLevelFromEnv(level))
+		}
+	}
+	if v, ok := os.LookupEnv(EnvFormat); ok {
+		switch strings.ToLower(v) {
+		case "json":
+			options = append(options, withJSONFromEnv(true))
+		case "text":
+			options = append(options, withJSONFromEnv(false))
+		default:
+			errs = append(errs, invalidEnvError(EnvFormat, v, errors.New("must be json or text")))
+		}
+	}
+	if v, ok := os.LookupEnv(EnvAddSource); ok {
+		addSource, err := strconv.ParseBool(v)
+		if err != nil {
+			errs = append(errs, invalidEnvError(EnvAddSource, v, err))
+		} else {
+			options = append(options, withAddSourceFromEnv(addSource))
+		}
+	}
+	if v, ok := os.LookupEnv(EnvRedactKeys); ok {
+		var keys []string
+		for _, k := range strings.Split(v, ",") {
+			if k = strings.TrimSpace(k); k != "" {
+				keys = append(keys, k)
+			}
+		}
+		options = append(options, WithRedactKeys(keys...))
+	}
+	return options, errors.Join(errs...)
+}
+
+// invalidEnvError returns the error wrapping [ErrInvalidEnv] for the variable name with the value v.
+func invalidEnvError(name, v string, err error) error {
+	return fmt.Errorf("%w: %s=%q: %v", ErrInvalidEnv, name, v, err)
+}
+
+// withLevelFromEnv specifies the log level of both the handler and the inner handler.
+func withLevelFromEnv(level slog.Level) HandlerOption {
+	return func(p *Properties) {
+		p.logLevel = level
+		p.updateSlogHandlerOptions(func(o *slog.HandlerOptions) {
+			o.Level = level
+		})
+	}
+}
+
+// withJSONFromEnv specifies whether to use JSON format, keeping the [slog.HandlerOptions].
+func withJSONFromEnv(json bool) HandlerOption {
+	return func(p *Properties) {
+		p.json = json
+	}
+}
+
+// withAddSourceFromEnv specifies [slog.HandlerOptions.AddSource] of the inner handler.
+func withAddSourceFromEnv(addSource bool) HandlerOption {
+	return func(p *Properties) {
+		p.updateSlogHandlerOptions(func(o *slog.HandlerOptions) {
+			o.AddSource = addSource
+		})
+	}
+}
+
+// updateSlogHandlerOptions updates a copy of the [slog.HandlerOptions] with fn,
+// so that the ones specified by [WithSlogHandlerSpecify] are not modified.
+func (p *Properties) updateSlogHandlerOptions(fn func(o *slog.HandlerOptions)) {
+	var o slog.HandlerOptions
+	if p.slogHandlerOptions != nil {
+		o = *p.slogHandlerOptions
+	}
+	fn(&o)
+	p.slogHandlerOptions = &o
+}
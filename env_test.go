@@ -0,0 +1,116 @@
+package altnrslog
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+)
+
+func TestOptionsFromEnv(t *testing.T) {
+	type want struct {
+		level      slog.Level
+		innerLevel slog.Leveler
+		json       bool
+		addSource  bool
+		redactKeys []string
+		err        bool
+	}
+	type test struct {
+		env  map[string]string
+		want want
+	}
+	tests := map[string]test{
+		"happy-path: unset": {
+			want: want{level: slog.LevelInfo},
+		},
+		"happy-path: all variables": {
+			env: map[string]string{
+				EnvLevel:      "debug",
+				EnvFormat:     "JSON",
+				EnvAddSource:  "true",
+				EnvRedactKeys: "password, authorization,,",
+			},
+			want: want{
+				level:      slog.LevelDebug,
+				innerLevel: slog.LevelDebug,
+				json:       true,
+				addSource:  true,
+				redactKeys: []string{"password", "authorization"},
+			},
+		},
+		"unhappy-path: invalid values": {
+			env: map[string]string{
+				EnvLevel:      "verbose",
+				EnvFormat:     "yaml",
+				EnvAddSource:  "maybe",
+				EnvRedactKeys: "password",
+			},
+			want: want{
+				level:      slog.LevelInfo,
+				redactKeys: []string{"password"},
+				err:        true,
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			for _, k := range []string{EnvLevel, EnvFormat, EnvAddSource, EnvRedactKeys} {
+				// t.Setenv restores the variable after the test.
+				t.Setenv(k, "")
+				os.Unsetenv(k)
+			}
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			options, err := OptionsFromEnv()
+			if (err != nil) != tt.want.err {
+				t.Fatalf("OptionsFromEnv() error = %v, want error %v", err, tt.want.err)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidEnv) {
+				t.Errorf("OptionsFromEnv() error = %v, want %v", err, ErrInvalidEnv)
+			}
+			p := buildProperties(options)
+			if p.logLevel != tt.want.level {
+				t.Errorf("logLevel = %v, want %v", p.logLevel, tt.want.level)
+			}
+			if p.json != tt.want.json {
+				t.Errorf("json = %v, want %v", p.json, tt.want.json)
+			}
+			var o slog.HandlerOptions
+			if p.slogHandlerOptions != nil {
+				o = *p.slogHandlerOptions
+			}
+			if o.Level != tt.want.innerLevel {
+				t.Errorf("slogHandlerOptions.Level = %v, want %v", o.Level, tt.want.innerLevel)
+			}
+			if o.AddSource != tt.want.addSource {
+				t.Errorf("slogHandlerOptions.AddSource = %v, want %v", o.AddSource, tt.want.addSource)
+			}
+			if len(p.redactKeys) != len(tt.want.redactKeys) {
+				t.Errorf("redactKeys = %v, want %v", p.redactKeys, tt.want.redactKeys)
+			}
+			for _, k := range tt.want.redactKeys {
+				if _, ok := p.redactKeys[k]; !ok {
+					t.Errorf("redactKeys = %v, want %v", p.redactKeys, tt.want.redactKeys)
+				}
+			}
+		})
+	}
+}
+
+func TestOptionsFromEnv_KeepsSlogHandlerOptions(t *testing.T) {
+	t.Setenv(EnvAddSource, "1")
+	options, err := OptionsFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	specified := &slog.HandlerOptions{Level: slog.LevelWarn}
+	p := buildProperties(append([]HandlerOption{WithSlogHandlerSpecify(true, specified)}, options...))
+	if specified.AddSource {
+		t.Error("the specified options must not be modified")
+	}
+	if !p.slogHandlerOptions.AddSource || p.slogHandlerOptions.Level != slog.LevelWarn {
+		t.Errorf("slogHandlerOptions = %+v, want AddSource and Level WARN", *p.slogHandlerOptions)
+	}
+}
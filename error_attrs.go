@@ -0,0 +1,186 @@
+package altnrslog
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+const (
+	// KeyErrorMessage is the attribute key of the error message expanded by [WithErrorExpansion].
+	KeyErrorMessage = "error.message"
+	// KeyErrorClass is the attribute key of the error class expanded by [WithErrorExpansion].
+	KeyErrorClass = "error.class"
+	// KeyErrorStack is the attribute key of the error stack trace expanded by [WithErrorExpansion].
+	KeyErrorStack = "error.stack"
+)
+
+// maxStackDepth is the maximum number of frames in the stack trace captured by [runtime.Callers].
+const maxStackDepth = 64
+
+// stackTracer is an error that carries the program counters of its stack trace.
+type stackTracer interface {
+	Callers() []uintptr
+}
+
+// expandErrors returns a copy of r whose first attribute with an error value is replaced by
+// the attributes named error.message, error.class and error.stack.
+// If r has no attribute with an error value, r is returned as is.
+func expandErrors(r slog.Record) slog.Record {
+	var target error
+	r.Attrs(func(a slog.Attr) bool {
+		target = errorOf(a)
+		return target == nil
+	})
+	if target == nil {
+		return r
+	}
+
+	expanded := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	done := false
+	r.Attrs(func(a slog.Attr) bool {
+		if done || errorOf(a) == nil {
+			expanded.AddAttrs(a)
+			return true
+		}
+		done = true
+		expanded.AddAttrs(attrsFromError(target)...)
+		return true
+	})
+	return expanded
+}
+
+// errorOf returns the error held by a, or nil.
+func errorOf(a slog.Attr) error {
+	if a.Value.Kind() != slog.KindAny {
+		return nil
+	}
+	err, _ := a.Value.Any().(error)
+	return err
+}
+
+// attrsFromError converts err to the attributes named error.message, error.class and error.stack.
+// The chains joined by [errors.Join] are unwrapped, and the class and the stack trace of the first error are used.
+func attrsFromError(err error) []slog.Attr {
+	leaves := leafErrors(err)
+	first := leaves[0]
+	return []slog.Attr{
+		slog.String(KeyErrorMessage, err.Error()),
+		slog.String(KeyErrorClass, errorClass(first)),
+		slog.String(KeyErrorStack, formatStack(errorStack(leaves))),
+	}
+}
+
+// leafErrors unwraps the errors joined by [errors.Join] or wrapped with multiple %w, in depth-first order.
+func leafErrors(err error) []error {
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return []error{err}
+	}
+	var leaves []error
+	for _, e := range joined.Unwrap() {
+		if e != nil {
+			leaves = append(leaves, leafErrors(e)...)
+		}
+	}
+	if len(leaves) == 0 {
+		return []error{err}
+	}
+	return leaves
+}
+
+// errorClass returns the class of err, which is the type of the innermost error wrapped by [fmt.Errorf].
+func errorClass(err error) string {
+	for {
+		if _, ok := err.(interface{ Unwrap() []error }); ok {
+			break
+		}
+		inner := errors.Unwrap(err)
+		if inner == nil || !isFmtWrapper(err) {
+			break
+		}
+		err = inner
+	}
+	return reflect.TypeOf(err).String()
+}
+
+// isFmtWrapper reports whether err is created by [fmt.Errorf] with %w, which carries no class of its own.
+func isFmtWrapper(err error) bool {
+	t := reflect.TypeOf(err).String()
+	return t == "*fmt.wrapError" || t == "*fmt.wrapErrors"
+}
+
+// errorStack returns the program counters of the stack trace carried by the errors or their wrapped errors.
+// If none carries a stack trace, the stack trace of the caller of [log/slog] is captured.
+func errorStack(errs []error) []uintptr {
+	for _, err := range errs {
+		for e := err; e != nil; e = errors.Unwrap(e) {
+			if pcs := callersOf(e); len(pcs) > 0 {
+				return pcs
+			}
+		}
+	}
+	return callersOfLogger()
+}
+
+// callersOf returns the program counters carried by err.
+//
+// Errors satisfying stackTracer, and errors with a StackTrace method returning a slice of program counters,
+// such as the ones created by github.com/pkg/errors, are supported.
+func callersOf(err error) []uintptr {
+	if st, ok := err.(stackTracer); ok {
+		return st.Callers()
+	}
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+		return nil
+	}
+	out := m.Type().Out(0)
+	if out.Kind() != reflect.Slice || out.Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+	frames := m.Call(nil)[0]
+	pcs := make([]uintptr, frames.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(frames.Index(i).Uint())
+	}
+	return pcs
+}
+
+// callersOfLogger returns the program counters of the stack trace, starting from the caller of [log/slog].
+func callersOfLogger() []uintptr {
+	pcs := make([]uintptr, maxStackDepth)
+	n := runtime.Callers(2, pcs)
+	pcs = pcs[:n]
+
+	start := 0
+	for i, pc := range pcs {
+		if fn := runtime.FuncForPC(pc - 1); fn != nil && strings.HasPrefix(fn.Name(), "log/slog.") {
+			start = i + 1
+		}
+	}
+	if start >= len(pcs) {
+		return pcs
+	}
+	return pcs[start:]
+}
+
+// formatStack formats the program counters like [runtime/debug.Stack].
+func formatStack(pcs []uintptr) string {
+	if len(pcs) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	frames := runtime.CallersFrames(pcs)
+	for {
+		f, more := frames.Next()
+		fmt.Fprintf(&b, "%s\n\t%s:%d\n", f.Function, f.File, f.Line)
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,163 @@
+package altnrslog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testError struct{}
+
+func (testError) Error() string { return "test error" }
+
+type testStackError struct {
+	pcs []uintptr
+}
+
+func (e *testStackError) Error() string { return "stack error" }
+
+func (e *testStackError) Callers() []uintptr { return e.pcs }
+
+type testFrame uintptr
+
+type testPkgError struct {
+	pcs []uintptr
+}
+
+func (e *testPkgError) Error() string { return "pkg error" }
+
+func (e *testPkgError) StackTrace() []testFrame {
+	frames := make([]testFrame, len(e.pcs))
+	for i, pc := range e.pcs {
+		frames[i] = testFrame(pc)
+	}
+	return frames
+}
+
+func testHelper_Callers() []uintptr {
+	pcs := make([]uintptr, 8)
+	n := runtime.Callers(1, pcs)
+	return pcs[:n]
+}
+
+func Test_attrsFromError(t *testing.T) {
+	pcs := testHelper_Callers()
+	type want struct {
+		message string
+		class   string
+		stack   string
+	}
+	type test struct {
+		args error
+		want want
+	}
+	tests := map[string]test{
+		"happy-path: plain error": {
+			args: testError{},
+			want: want{
+				message: "test error",
+				class:   "altnrslog.testError",
+				stack:   "altnrslog.Test_attrsFromError",
+			},
+		},
+		"happy-path: wrapped by fmt.Errorf": {
+			args: fmt.Errorf("context: %w", testError{}),
+			want: want{
+				message: "context: test error",
+				class:   "altnrslog.testError",
+			},
+		},
+		"happy-path: joined": {
+			args: errors.Join(errors.New("first"), testError{}),
+			want: want{
+				message: "first\ntest error",
+				class:   "*errors.errorString",
+			},
+		},
+		"happy-path: carries callers": {
+			args: fmt.Errorf("context: %w", &testStackError{pcs: pcs}),
+			want: want{
+				message: "context: stack error",
+				class:   "*altnrslog.testStackError",
+				stack:   "altnrslog.testHelper_Callers",
+			},
+		},
+		"happy-path: carries stack trace": {
+			args: errors.Join(testError{}, &testPkgError{pcs: pcs}),
+			want: want{
+				message: "test error\npkg error",
+				class:   "altnrslog.testError",
+				stack:   "altnrslog.testHelper_Callers",
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := attrsFromError(tt.args)
+			if len(got) != 3 {
+				t.Fatalf("len(attrsFromError()) = %d, want 3", len(got))
+			}
+			if got[0].Key != KeyErrorMessage || got[0].Value.String() != tt.want.message {
+				t.Errorf("%s = %q, want %q", got[0].Key, got[0].Value, tt.want.message)
+			}
+			if got[1].Key != KeyErrorClass || got[1].Value.String() != tt.want.class {
+				t.Errorf("%s = %q, want %q", got[1].Key, got[1].Value, tt.want.class)
+			}
+			if got[2].Key != KeyErrorStack || !strings.Contains(got[2].Value.String(), tt.want.stack) {
+				t.Errorf("%s = %q, want containing %q", got[2].Key, got[2].Value, tt.want.stack)
+			}
+		})
+	}
+}
+
+func Test_expandErrors(t *testing.T) {
+	r := slog.NewRecord(time.Time{}, slog.LevelError, "msg", 0)
+	r.AddAttrs(slog.String("foo", "bar"), slog.Any("err", testError{}), slog.Any("other", errors.New("other")))
+
+	got := expandErrors(r)
+	var keys []string
+	got.Attrs(func(a slog.Attr) bool {
+		keys = append(keys, a.Key)
+		return true
+	})
+	want := []string{"foo", KeyErrorMessage, KeyErrorClass, KeyErrorStack, "other"}
+	if strings.Join(keys, ",") != strings.Join(want, ",") {
+		t.Errorf("keys = %v, want %v", keys, want)
+	}
+
+	plain := slog.NewRecord(time.Time{}, slog.LevelError, "msg", 0)
+	plain.AddAttrs(slog.String("foo", "bar"))
+	if got := expandErrors(plain); got.NumAttrs() != 1 {
+		t.Errorf("NumAttrs() = %d, want 1", got.NumAttrs())
+	}
+}
+
+func TestTransactionalHandler_Handle_WithErrorExpansion(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(NewTransactionalHandler(nil, nil, testHelper_BufferOption(buf), WithErrorExpansion()))
+	logger.Error("failed", slog.Any("err", testError{}))
+
+	lines := testHelper_JSONLines(t, buf)
+	if len(lines) != 1 {
+		t.Fatalf("logged %d records, want 1", len(lines))
+	}
+	l := lines[0]
+	if _, ok := l["err"]; ok {
+		t.Error("err must be expanded")
+	}
+	if l[KeyErrorMessage] != "test error" {
+		t.Errorf("%s = %v, want %v", KeyErrorMessage, l[KeyErrorMessage], "test error")
+	}
+	if l[KeyErrorClass] != "altnrslog.testError" {
+		t.Errorf("%s = %v, want %v", KeyErrorClass, l[KeyErrorClass], "altnrslog.testError")
+	}
+	stack, _ := l[KeyErrorStack].(string)
+	if !strings.HasPrefix(stack, "github.com/miyamo2/altnrslog.TestTransactionalHandler_Handle_WithErrorExpansion") {
+		t.Errorf("%s = %v, want starting from the logging call", KeyErrorStack, stack)
+	}
+}
@@ -1,6 +1,7 @@
 package altnrslog_test
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -8,9 +9,11 @@ import (
 	"log/slog"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/miyamo2/altnrslog"
 	"github.com/newrelic/go-agent/v3/newrelic"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func Example() {
@@ -196,3 +199,293 @@ func ExampleNewTransactionalHandler_withSlogHandlerSpecify() {
 
 	slog.New(txHandler)
 }
+
+func ExampleNewRotatingFileWriter() {
+	app, err := newrelic.NewApplication(
+		newrelic.ConfigAppName(os.Getenv("NEW_RELIC_CONFIG_APP_NAME")),
+		newrelic.ConfigLicense(os.Getenv("NEW_RELIC_CONFIG_LICENSE")),
+		newrelic.ConfigAppLogForwardingEnabled(true),
+	)
+	tx := app.StartTransaction("ExampleNewRotatingFileWriter")
+	if err != nil {
+		panic(err)
+	}
+
+	rw, err := altnrslog.NewRotatingFileWriter("/var/log/app/app.log",
+		altnrslog.WithMaxSize(100<<20),
+		altnrslog.WithRotationInterval(24*time.Hour),
+		altnrslog.WithMaxBackups(7),
+		altnrslog.WithCompress(true))
+	if err != nil {
+		panic(err)
+	}
+	defer rw.Close()
+
+	txHandler := altnrslog.NewTransactionalHandler(app, tx, altnrslog.WithInnerWriter(rw))
+
+	slog.New(txHandler)
+}
+
+func ExampleNewHandlerFactory() {
+	nr, err := newrelic.NewApplication(
+		newrelic.ConfigAppName(os.Getenv("NEW_RELIC_CONFIG_APP_NAME")),
+		newrelic.ConfigLicense(os.Getenv("NEW_RELIC_CONFIG_LICENSE")),
+		newrelic.ConfigAppLogForwardingEnabled(true),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	factory := altnrslog.NewHandlerFactory(nr, altnrslog.WithSlogHandlerSpecify(true, nil))
+
+	httpHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		logger := slog.New(factory.New(newrelic.FromContext(ctx)))
+		logger.InfoContext(ctx, "Hello, World!")
+		w.Write([]byte("Hello, World!"))
+	})
+	http.Handle(newrelic.WrapHandle(nr, "/hello", httpHandler))
+
+	log.Fatal(http.ListenAndServe(":8080", nil))
+}
+
+func ExampleSpanContextMetadataProvider() {
+	app, err := newrelic.NewApplication(
+		newrelic.ConfigAppName(os.Getenv("NEW_RELIC_CONFIG_APP_NAME")),
+		newrelic.ConfigLicense(os.Getenv("NEW_RELIC_CONFIG_LICENSE")),
+		newrelic.ConfigAppLogForwardingEnabled(true),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	httpHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		provider := altnrslog.SpanContextMetadataProvider(
+			trace.SpanContextFromContext(ctx),
+			altnrslog.ApplicationMetadataProvider(app))
+		logger := slog.New(altnrslog.NewTransactionalHandler(app, nil, altnrslog.WithMetadataProvider(provider)))
+		logger.InfoContext(ctx, "Hello, World!")
+		w.Write([]byte("Hello, World!"))
+	})
+	http.Handle("/hello", httpHandler)
+
+	log.Fatal(http.ListenAndServe(":8080", nil))
+}
+
+func ExampleStartTransactionFromHeaders() {
+	app, err := newrelic.NewApplication(
+		newrelic.ConfigAppName(os.Getenv("NEW_RELIC_CONFIG_APP_NAME")),
+		newrelic.ConfigLicense(os.Getenv("NEW_RELIC_CONFIG_LICENSE")),
+		newrelic.ConfigAppLogForwardingEnabled(true),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	// e.g. headers of a Kafka record
+	recordHeaders := map[string][]byte{
+		"traceparent": []byte("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"),
+	}
+
+	ctx, logger, tx := altnrslog.StartTransactionFromHeaders(context.Background(), app, "consume",
+		newrelic.TransportKafka, altnrslog.HeadersFromBytesMap(recordHeaders))
+	defer tx.End()
+
+	logger.InfoContext(ctx, "message received")
+}
+
+func ExampleRunInTransaction() {
+	app, err := newrelic.NewApplication(
+		newrelic.ConfigAppName(os.Getenv("NEW_RELIC_CONFIG_APP_NAME")),
+		newrelic.ConfigLicense(os.Getenv("NEW_RELIC_CONFIG_LICENSE")),
+		newrelic.ConfigAppLogForwardingEnabled(true),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	go func() {
+		err := altnrslog.RunInTransaction(context.Background(), app, "cleanup",
+			func(ctx context.Context, logger *slog.Logger) error {
+				logger.InfoContext(ctx, "cleanup started")
+				return nil
+			})
+		if err != nil {
+			log.Println(err)
+		}
+	}()
+}
+
+func ExampleOptionsFromEnv() {
+	nr, err := newrelic.NewApplication(
+		newrelic.ConfigAppName(os.Getenv("NEW_RELIC_CONFIG_APP_NAME")),
+		newrelic.ConfigLicense(os.Getenv("NEW_RELIC_CONFIG_LICENSE")),
+		newrelic.ConfigAppLogForwardingEnabled(true),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	options := []altnrslog.HandlerOption{altnrslog.WithSlogHandlerSpecify(true, nil)}
+	envOptions, err := altnrslog.OptionsFromEnv()
+	if err != nil {
+		log.Printf("ignoring invalid logging configuration: %v", err)
+	}
+	// the options specified by the environment variables override the ones specified in code.
+	factory := altnrslog.NewHandlerFactory(nr, append(options, envOptions...)...)
+
+	httpHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		logger := slog.New(factory.New(newrelic.FromContext(ctx)))
+		logger.InfoContext(ctx, "Hello, World!")
+		w.Write([]byte("Hello, World!"))
+	})
+	http.Handle(newrelic.WrapHandle(nr, "/hello", httpHandler))
+
+	log.Fatal(http.ListenAndServe(":8080", nil))
+}
+
+func ExampleWatchConfig() {
+	nr, err := newrelic.NewApplication(
+		newrelic.ConfigAppName(os.Getenv("NEW_RELIC_CONFIG_APP_NAME")),
+		newrelic.ConfigLicense(os.Getenv("NEW_RELIC_CONFIG_LICENSE")),
+		newrelic.ConfigAppLogForwardingEnabled(true),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	watcher, err := altnrslog.WatchConfig("/etc/app/altnrslog.yaml",
+		altnrslog.WithPollInterval(10*time.Second),
+		altnrslog.WithReloadErrorHandler(func(err error) {
+			log.Printf("keeping the current logging configuration: %v", err)
+		}))
+	if err != nil {
+		panic(err)
+	}
+	defer watcher.Close()
+
+	factory := altnrslog.NewHandlerFactory(nr, watcher.HandlerOptions()...)
+
+	httpHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		logger := slog.New(factory.New(newrelic.FromContext(ctx)))
+		logger.DebugContext(ctx, "logged only while the level in the file is debug")
+		w.Write([]byte("Hello, World!"))
+	})
+	http.Handle(newrelic.WrapHandle(nr, "/hello", httpHandler))
+
+	log.Fatal(http.ListenAndServe(":8080", nil))
+}
+
+func ExampleNewAdminHandler() {
+	nr, err := newrelic.NewApplication(
+		newrelic.ConfigAppName(os.Getenv("NEW_RELIC_CONFIG_APP_NAME")),
+		newrelic.ConfigLicense(os.Getenv("NEW_RELIC_CONFIG_LICENSE")),
+		newrelic.ConfigAppLogForwardingEnabled(true),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	levels := altnrslog.NewLevelController(nil)
+	factory := altnrslog.NewHandlerFactory(nr, altnrslog.WithLeveler(levels))
+
+	httpHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		logger := altnrslog.Named(slog.New(factory.New(newrelic.FromContext(ctx))), "billing.invoice")
+		// logged while the level of billing or billing.invoice is overridden to debug, e.g.
+		//   curl -X PUT -d '{"level": "debug"}' localhost:8081/admin/log/overrides/billing
+		logger.DebugContext(ctx, "Hello, World!")
+		w.Write([]byte("Hello, World!"))
+	})
+	http.Handle(newrelic.WrapHandle(nr, "/hello", httpHandler))
+
+	admin := http.NewServeMux()
+	admin.Handle("/admin/log/", http.StripPrefix("/admin/log", altnrslog.NewAdminHandler(levels)))
+	go http.ListenAndServe("localhost:8081", admin)
+
+	log.Fatal(http.ListenAndServe(":8080", nil))
+}
+
+func ExampleDebugMiddleware() {
+	nr, err := newrelic.NewApplication(
+		newrelic.ConfigAppName(os.Getenv("NEW_RELIC_CONFIG_APP_NAME")),
+		newrelic.ConfigLicense(os.Getenv("NEW_RELIC_CONFIG_LICENSE")),
+		newrelic.ConfigAppLogForwardingEnabled(true),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	factory := altnrslog.NewHandlerFactory(nr, altnrslog.WithSlogHandlerSpecify(true, nil))
+
+	httpHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		logger, _ := altnrslog.FromContext(ctx)
+		// logged only for the requests of the customer under investigation.
+		logger.DebugContext(ctx, "Hello, World!")
+		w.Write([]byte("Hello, World!"))
+	})
+
+	loggerMiddleware := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			ctx, _ = altnrslog.StoreToContext(ctx, slog.New(factory.New(newrelic.FromContext(ctx))))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+	debugMiddleware := func(next http.Handler) http.Handler {
+		return altnrslog.DebugMiddleware(next, func(r *http.Request) bool {
+			return r.Header.Get("X-Customer-ID") == "customer-under-investigation"
+		})
+	}
+	http.Handle(newrelic.WrapHandle(nr, "/hello", loggerMiddleware(debugMiddleware(httpHandler))))
+
+	log.Fatal(http.ListenAndServe(":8080", nil))
+}
+
+func ExampleWithTailBuffering() {
+	app, err := newrelic.NewApplication(
+		newrelic.ConfigAppName(os.Getenv("NEW_RELIC_CONFIG_APP_NAME")),
+		newrelic.ConfigLicense(os.Getenv("NEW_RELIC_CONFIG_LICENSE")),
+		newrelic.ConfigAppLogForwardingEnabled(true),
+	)
+	tx := app.StartTransaction("ExampleWithTailBuffering")
+	if err != nil {
+		panic(err)
+	}
+	defer tx.End()
+
+	txHandler := altnrslog.NewTransactionalHandler(app, tx,
+		altnrslog.WithSlogHandlerSpecify(true, &slog.HandlerOptions{Level: slog.LevelDebug}),
+		altnrslog.WithLogLevel(slog.LevelDebug),
+		altnrslog.WithTailBuffering(slog.LevelInfo, 1000))
+	logger := slog.New(txHandler)
+
+	ctx := context.Background()
+	// buffered, and written only because of the following error.
+	logger.DebugContext(ctx, "calling the payment service")
+	logger.ErrorContext(ctx, "payment failed")
+}
+
+func ExampleEndTransaction() {
+	app, err := newrelic.NewApplication(
+		newrelic.ConfigAppName(os.Getenv("NEW_RELIC_CONFIG_APP_NAME")),
+		newrelic.ConfigLicense(os.Getenv("NEW_RELIC_CONFIG_LICENSE")),
+		newrelic.ConfigAppLogForwardingEnabled(true),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	tx := app.StartTransaction("ExampleEndTransaction")
+	ctx := newrelic.NewContext(context.Background(), tx)
+	logger := slog.New(altnrslog.NewTransactionalHandler(app, tx, altnrslog.WithTransactionSummary()))
+	ctx, _ = altnrslog.StoreToContext(ctx, logger)
+	// emits the summary record, then ends tx.
+	defer altnrslog.EndTransaction(ctx)
+
+	logger.InfoContext(ctx, "Hello, World!")
+}
@@ -0,0 +1,38 @@
+package altnrslog
+
+import (
+	"log/slog"
+	"sync/atomic"
+)
+
+// filters is the set of the settings applied to each record, which can be swapped while the handlers are live.
+type filters struct {
+	redactKeys map[string]struct{}
+	sampling   *sampling
+}
+
+// liveFilters holds the filters swapped by [ConfigWatcher].
+type liveFilters struct {
+	current atomic.Pointer[filters]
+}
+
+// load returns the current filters.
+func (l *liveFilters) load() *filters {
+	return l.current.Load()
+}
+
+// store swaps the current filters for f.
+func (l *liveFilters) store(f *filters) {
+	l.current.Store(f)
+}
+
+// apply returns r with the filters applied, and reports whether r is to be handled.
+func (f *filters) apply(r slog.Record) (slog.Record, bool) {
+	if !f.sampling.keep(r.Level) {
+		return r, false
+	}
+	if len(f.redactKeys) != 0 {
+		r = redactRecord(f.redactKeys, r)
+	}
+	return r, true
+}
@@ -0,0 +1,136 @@
+package altnrslog
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+// linkingSuffixSize is the estimated size of the linking metadata appended to a record.
+const linkingSuffixSize = 256
+
+// maxPooledBufferSize is the maximum capacity of the buffers returned to bufferPool,
+// so that a few large records do not keep large buffers alive.
+const maxPooledBufferSize = 64 << 10
+
+// bufferPool pools the buffers the records are formatted into by [forwardingWriter.forward].
+var bufferPool = sync.Pool{
+	New: func() any {
+		return &bytes.Buffer{}
+	},
+}
+
+// putBuffer resets buf and returns it to bufferPool.
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBufferSize {
+		return
+	}
+	buf.Reset()
+	bufferPool.Put(buf)
+}
+
+// forwardingWriter forwards each formatted record to New Relic as log data,
+// and writes it to the inner writer with the linking metadata appended, like [logWriter.LogWriter].
+//
+// Unlike [logWriter.LogWriter], the severity and the timestamp of the record are forwarded too.
+// The inner handlers write to it through [recordWriter], which holds them for the record being handled.
+//
+// [logWriter.LogWriter]: https://pkg.go.dev/github.com/newrelic/go-agent/v3/integrations/logcontext-v2/logWriter#LogWriter
+type forwardingWriter struct {
+	out io.Writer
+	app *newrelic.Application
+	tx  *newrelic.Transaction
+}
+
+// newForwardingWriter is constructor for forwardingWriter.
+func newForwardingWriter(out io.Writer, app *newrelic.Application, tx *newrelic.Transaction) *forwardingWriter {
+	return &forwardingWriter{
+		out: out,
+		app: app,
+		tx:  tx,
+	}
+}
+
+// forward forwards p to New Relic with the severity and the timestamp,
+// and writes it to the inner writer with the linking metadata appended.
+func (w *forwardingWriter) forward(p []byte, severity string, timestamp int64) (n int, err error) {
+	data := newrelic.LogData{
+		Timestamp: timestamp,
+		Severity:  severity,
+		Message:   string(p),
+	}
+	buf := bufferPool.Get().(*bytes.Buffer)
+	defer putBuffer(buf)
+	buf.Grow(len(p) + linkingSuffixSize)
+	buf.Write(bytes.TrimRight(p, "\n"))
+	if w.tx != nil {
+		w.tx.RecordLog(data)
+		newrelic.EnrichLog(buf, newrelic.FromTxn(w.tx))
+	} else {
+		w.app.RecordLog(data)
+		newrelic.EnrichLog(buf, newrelic.FromApp(w.app))
+	}
+	buf.WriteByte('\n')
+	if _, err := w.out.Write(buf.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// recordWriter is the [io.Writer] an instance of the inner handler writes to.
+//
+// The handler acquires it for each record by [recordWriter.acquire], so that the records written are forwarded
+// with the severity and the time of the record. It is not held while waiting, so a record logged during formatting,
+// such as by a [slog.LogValuer], does not deadlock; the handler falls back to a spare instance instead.
+type recordWriter struct {
+	fw   *forwardingWriter
+	busy atomic.Bool
+
+	// mu guards the severity and the timestamp, which may be read by an inner handler writing outside Handle.
+	mu        sync.Mutex
+	severity  string
+	timestamp int64
+}
+
+// newRecordWriter is constructor for recordWriter.
+func newRecordWriter(fw *forwardingWriter) *recordWriter {
+	return &recordWriter{fw: fw}
+}
+
+// acquire acquires the writer for the record with the severity and the time.
+// It reports false without waiting if the writer is held for another record.
+func (w *recordWriter) acquire(severity string, t time.Time) bool {
+	if !w.busy.CompareAndSwap(false, true) {
+		return false
+	}
+	var timestamp int64
+	if !t.IsZero() {
+		timestamp = t.UnixMilli()
+	}
+	w.mu.Lock()
+	w.severity = severity
+	w.timestamp = timestamp
+	w.mu.Unlock()
+	return true
+}
+
+// release resets the severity and the time, and releases the writer.
+func (w *recordWriter) release() {
+	w.mu.Lock()
+	w.severity = ""
+	w.timestamp = 0
+	w.mu.Unlock()
+	w.busy.Store(false)
+}
+
+// Write forwards p with the severity and the time of the record the writer is held for.
+func (w *recordWriter) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	severity, timestamp := w.severity, w.timestamp
+	w.mu.Unlock()
+	return w.fw.forward(p, severity, timestamp)
+}
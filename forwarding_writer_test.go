@@ -0,0 +1,69 @@
+package altnrslog
+
+import (
+	"testing"
+	"time"
+)
+
+type testWriterFunc struct {
+	fn func(p []byte)
+}
+
+func (w *testWriterFunc) Write(p []byte) (int, error) {
+	w.fn(p)
+	return len(p), nil
+}
+
+func Test_recordWriter_acquire(t *testing.T) {
+	var got []string
+	out := &testWriterFunc{}
+	sut := newRecordWriter(newForwardingWriter(out, nil, nil))
+	out.fn = func(p []byte) {
+		got = append(got, string(p))
+		if sut.severity != "WARN" {
+			t.Errorf("severity = %s, want %s", sut.severity, "WARN")
+		}
+		if want := time.Unix(1, 0).UnixMilli(); sut.timestamp != want {
+			t.Errorf("timestamp = %d, want %d", sut.timestamp, want)
+		}
+	}
+	if !sut.acquire("WARN", time.Unix(1, 0)) {
+		t.Fatal("acquire() = false, want true")
+	}
+	if sut.acquire("INFO", time.Unix(2, 0)) {
+		t.Error("acquire() while held = true, want false")
+	}
+	p := []byte("foo\n")
+	n, err := sut.Write(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != len(p) {
+		t.Errorf("Write() = %d, want %d", n, len(p))
+	}
+	sut.release()
+	if len(got) != 1 || got[0] != "foo\n" {
+		t.Errorf("written = %q, want %q", got, []string{"foo\n"})
+	}
+	if sut.severity != "" || sut.timestamp != 0 {
+		t.Errorf("severity, timestamp = %s, %d, want reset", sut.severity, sut.timestamp)
+	}
+	if !sut.acquire("INFO", time.Unix(2, 0)) {
+		t.Error("acquire() after release = false, want true")
+	}
+}
+
+func Test_forwardingWriter_forward_WithTransaction(t *testing.T) {
+	app := testHelper_Application(t)
+	tx := app.StartTransaction("test")
+	defer tx.End()
+
+	var got []byte
+	sut := newForwardingWriter(&testWriterFunc{fn: func(p []byte) { got = append(got, p...) }}, app, tx)
+	if _, err := sut.forward([]byte("foo\n"), "INFO", 0); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) == 0 || got[len(got)-1] != '\n' {
+		t.Errorf("written = %q, want a line", got)
+	}
+}
@@ -0,0 +1,102 @@
+package altnrslog
+
+import (
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+// HandlerFactory creates [TransactionalHandler] for each transaction from the options resolved once.
+//
+// It is safe for concurrent use, so it is intended to be created at startup and shared by all requests.
+// The state of each transaction, such as the writer forwarding the records and the linking metadata cache,
+// is allocated at once by [HandlerFactory.New].
+type HandlerFactory struct {
+	app                *newrelic.Application
+	props              *Properties
+	innerWriter        io.Writer
+	slogHandlerOptions *slog.HandlerOptions
+	staticFilters      *filters
+}
+
+// NewHandlerFactory is constructor for [HandlerFactory].
+func NewHandlerFactory(app *newrelic.Application, options ...HandlerOption) *HandlerFactory {
+	p := buildProperties(options)
+	iw := p.innerWriter
+	if iw == nil {
+		iw = os.Stdout
+	}
+	return &HandlerFactory{
+		app:                app,
+		props:              p,
+		innerWriter:        iw,
+		slogHandlerOptions: p.resolveSlogHandlerOptions(),
+		staticFilters:      &filters{redactKeys: p.redactKeys, sampling: p.sampling},
+	}
+}
+
+// New creates a new [TransactionalHandler] bound to tx.
+func (f *HandlerFactory) New(tx *newrelic.Transaction) *TransactionalHandler {
+	b, inner := f.bind(tx)
+	return &TransactionalHandler{
+		handler:    inner,
+		tx:         tx,
+		level:      f.props.logLevel,
+		factory:    f,
+		metadata:   &b.metadata,
+		writer:     &b.writer,
+		state:      &b.state,
+		redactedBy: f.filters(),
+	}
+}
+
+// filters returns the filters applied to each record.
+func (f *HandlerFactory) filters() *filters {
+	if f.props.liveFilters != nil {
+		if live := f.props.liveFilters.load(); live != nil {
+			return live
+		}
+	}
+	return f.staticFilters
+}
+
+// metadataProvider returns the [MetadataProvider] for the handler bound to tx.
+func (f *HandlerFactory) metadataProvider(tx *newrelic.Transaction) MetadataProvider {
+	if f.props.metadataProvider != nil {
+		return f.props.metadataProvider
+	}
+	return tx
+}
+
+// boundTransaction is the state shared by the handlers bound to a transaction, allocated at once for each transaction.
+type boundTransaction struct {
+	forwarder forwardingWriter
+	writer    recordWriter
+	metadata  metadataCache
+	state     transactionState
+}
+
+// bind allocates the state of the handlers bound to tx, and creates the [slog.Handler] to be wrapped writing to it.
+func (f *HandlerFactory) bind(tx *newrelic.Transaction) (*boundTransaction, slog.Handler) {
+	b := &boundTransaction{
+		forwarder: forwardingWriter{out: f.innerWriter, app: f.app, tx: tx},
+		metadata:  metadataCache{src: f.metadataProvider(tx)},
+	}
+	b.writer.fw = &b.forwarder
+	b.state.init(f.props)
+	return b, f.innerHandler(&b.writer)
+}
+
+// innerHandler creates the [slog.Handler] to be wrapped, writing to w.
+func (f *HandlerFactory) innerHandler(w io.Writer) slog.Handler {
+	p := f.props
+	if p.innerHandlerProvider != nil {
+		return p.innerHandlerProvider(w)
+	}
+	if p.json {
+		return slog.NewJSONHandler(w, f.slogHandlerOptions)
+	}
+	return slog.NewTextHandler(w, f.slogHandlerOptions)
+}
@@ -0,0 +1,110 @@
+package altnrslog
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+
+	mslog "github.com/miyamo2/altnrslog/internal/mock"
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+func TestHandlerFactory_New(t *testing.T) {
+	type want struct {
+		handler slog.Handler
+		level   slog.Level
+	}
+	type test struct {
+		args []HandlerOption
+		want want
+	}
+	tests := map[string]test{
+		"happy-path: default": {
+			want: want{
+				handler: &slog.TextHandler{},
+				level:   slog.LevelInfo,
+			},
+		},
+		"happy-path: json": {
+			args: []HandlerOption{WithSlogHandlerSpecify(true, nil), WithLogLevel(slog.LevelWarn)},
+			want: want{
+				handler: &slog.JSONHandler{},
+				level:   slog.LevelWarn,
+			},
+		},
+		"happy-path: inner handler provider": {
+			args: []HandlerOption{WithInnerHandlerProvider(func(w io.Writer) slog.Handler {
+				return &mslog.MockHandler{}
+			})},
+			want: want{
+				handler: &mslog.MockHandler{},
+				level:   slog.LevelInfo,
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			app := newrelic.Application{}
+			tx := newrelic.Transaction{}
+			sut := NewHandlerFactory(&app, tt.args...)
+			got := sut.New(&tx)
+			if got.Transaction() != &tx {
+				t.Errorf("New().Transaction() = %p, want %p", got.Transaction(), &tx)
+			}
+			if got.Level() != tt.want.level {
+				t.Errorf("New().Level() = %v, want %v", got.Level(), tt.want.level)
+			}
+			if gotType, wantType := fmt.Sprintf("%T", got.Inner()), fmt.Sprintf("%T", tt.want.handler); gotType != wantType {
+				t.Errorf("New().Inner() = %s, want %s", gotType, wantType)
+			}
+			if got.factory != sut {
+				t.Errorf("New().factory = %p, want %p", got.factory, sut)
+			}
+		})
+	}
+}
+
+func TestHandlerFactory_New_IndependentHandlers(t *testing.T) {
+	app := newrelic.Application{}
+	sut := NewHandlerFactory(&app, WithSlogHandlerSpecify(true, nil))
+	tx1 := newrelic.Transaction{}
+	tx2 := newrelic.Transaction{}
+	h1 := sut.New(&tx1)
+	h2 := sut.New(&tx2)
+	if h1.Inner() == h2.Inner() {
+		t.Error("New() must build a dedicated inner handler for each transaction")
+	}
+	if h1.Transaction() == h2.Transaction() {
+		t.Error("New() must bind each handler to its own transaction")
+	}
+}
+
+func BenchmarkNewTransactionalHandler(b *testing.B) {
+	app := newrelic.Application{}
+	tx := newrelic.Transaction{}
+	opts := []HandlerOption{
+		WithInnerWriter(io.Discard),
+		WithSlogHandlerSpecify(true, &slog.HandlerOptions{AddSource: true}),
+		WithLogLevel(slog.LevelDebug),
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		NewTransactionalHandler(&app, &tx, opts...)
+	}
+}
+
+func BenchmarkHandlerFactory_New(b *testing.B) {
+	app := newrelic.Application{}
+	tx := newrelic.Transaction{}
+	factory := NewHandlerFactory(&app,
+		WithInnerWriter(io.Discard),
+		WithSlogHandlerSpecify(true, &slog.HandlerOptions{AddSource: true}),
+		WithLogLevel(slog.LevelDebug))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		factory.New(&tx)
+	}
+}
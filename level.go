@@ -0,0 +1,76 @@
+package altnrslog
+
+import (
+	"log/slog"
+	"math"
+)
+
+// LevelMapping is the representation of a [slog.Level].
+type LevelMapping struct {
+	// Name is the name of the level rendered in records.
+	Name string
+	// Severity is the level forwarded to New Relic. if empty, Name is used.
+	Severity string
+}
+
+// WithLevelMappings specifies the representation of levels, such as custom levels like LevelTrace = -8 or LevelFatal = 12,
+// which are otherwise rendered as DEBUG-4 or ERROR+4.
+//
+// The names are applied to the level attribute by [slog.HandlerOptions.ReplaceAttr] of the inner handler,
+// after the ReplaceAttr specified by [WithSlogHandlerSpecify] if any.
+// With [WithInnerHandlerProvider], only the severities are applied.
+func WithLevelMappings(mappings map[slog.Level]LevelMapping) HandlerOption {
+	return func(p *Properties) {
+		p.levelMappings = mappings
+	}
+}
+
+// levelName returns the name of level.
+func (p *Properties) levelName(level slog.Level) string {
+	if m, ok := p.levelMappings[level]; ok && m.Name != "" {
+		return m.Name
+	}
+	return level.String()
+}
+
+// severity returns the level forwarded to New Relic.
+func (p *Properties) severity(level slog.Level) string {
+	if m, ok := p.levelMappings[level]; ok && m.Severity != "" {
+		return m.Severity
+	}
+	return p.levelName(level)
+}
+
+// minLevel is the level of the inner handler when [WithLeveler] is specified, which lets all the records through.
+const minLevel = slog.Level(math.MinInt)
+
+// resolveSlogHandlerOptions returns [slog.HandlerOptions] for the inner handler with the level names applied.
+func (p *Properties) resolveSlogHandlerOptions() *slog.HandlerOptions {
+	if len(p.levelMappings) == 0 && p.leveler == nil {
+		return p.slogHandlerOptions
+	}
+	var o slog.HandlerOptions
+	if p.slogHandlerOptions != nil {
+		o = *p.slogHandlerOptions
+	}
+	if p.leveler != nil {
+		o.Level = minLevel
+	}
+	if len(p.levelMappings) == 0 {
+		return &o
+	}
+	replace := o.ReplaceAttr
+	o.ReplaceAttr = func(groups []string, a slog.Attr) slog.Attr {
+		if replace != nil {
+			a = replace(groups, a)
+		}
+		if len(groups) != 0 || a.Key != slog.LevelKey {
+			return a
+		}
+		if level, ok := a.Value.Any().(slog.Level); ok {
+			a.Value = slog.StringValue(p.levelName(level))
+		}
+		return a
+	}
+	return &o
+}
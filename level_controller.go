@@ -0,0 +1,145 @@
+package altnrslog
+
+import (
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// KeyLogger is the attribute key of the logger name, which [LevelController] resolves the level overrides by.
+const KeyLogger = "logger"
+
+// LevelController is a [slog.Leveler] that controls the levels of [TransactionalHandler] at runtime.
+//
+// It holds the global level, the level overrides per logger name, and the temporary debug mode.
+// The logger name of a handler is the value of the [KeyLogger] attribute added by [Named] or [slog.Logger.With]
+// outside any group. The names form a hierarchy separated by ".", so the override of billing applies to
+// billing.invoice unless billing.invoice has its own.
+//
+// Pass it to [WithLeveler] to take effect. It is safe for concurrent use.
+type LevelController struct {
+	global     *slog.LevelVar
+	overrides  atomic.Pointer[map[string]slog.Level]
+	debugUntil atomic.Int64
+	now        func() time.Time
+}
+
+// namedLeveler is a [slog.Leveler] that resolves the level by the logger name.
+//
+// [*LevelController] satisfies it.
+type namedLeveler interface {
+	slog.Leveler
+	levelFor(name string) slog.Level
+}
+
+// LevelState is the state of [LevelController].
+type LevelState struct {
+	// Level is the global level.
+	Level slog.Level `json:"level"`
+	// Overrides are the level overrides per logger name.
+	Overrides map[string]slog.Level `json:"overrides"`
+	// DebugUntil is the time the temporary debug mode ends at. if nil, the mode is not active.
+	DebugUntil *time.Time `json:"debug_until,omitempty"`
+}
+
+// NewLevelController is constructor for [LevelController].
+// The global level is held by global, so that it can be shared with [ConfigWatcher.Level].
+// if global is nil, a new [slog.LevelVar] at [slog.LevelInfo] is used.
+func NewLevelController(global *slog.LevelVar) *LevelController {
+	if global == nil {
+		global = &slog.LevelVar{}
+	}
+	c := &LevelController{global: global, now: time.Now}
+	c.overrides.Store(&map[string]slog.Level{})
+	return c
+}
+
+// Level returns the global level, lowered to [slog.LevelDebug] while the temporary debug mode is active.
+func (c *LevelController) Level() slog.Level {
+	return c.lower(c.global.Level())
+}
+
+// levelFor returns the level of the logger named name, resolved from the hierarchy of the names separated by ".".
+// For billing.invoice, the override of billing.invoice, then billing, then the global level is returned.
+func (c *LevelController) levelFor(name string) slog.Level {
+	overrides := *c.overrides.Load()
+	for name != "" && len(overrides) != 0 {
+		if level, ok := overrides[name]; ok {
+			return c.lower(level)
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+	return c.Level()
+}
+
+// lower returns level lowered to [slog.LevelDebug] while the temporary debug mode is active.
+func (c *LevelController) lower(level slog.Level) slog.Level {
+	if until := c.debugUntil.Load(); until != 0 && c.now().UnixNano() < until {
+		return min(level, slog.LevelDebug)
+	}
+	return level
+}
+
+// SetLevel sets the global level.
+func (c *LevelController) SetLevel(level slog.Level) {
+	c.global.Set(level)
+}
+
+// SetOverride sets the level of the logger named name and its descendants.
+func (c *LevelController) SetOverride(name string, level slog.Level) {
+	c.updateOverrides(func(m map[string]slog.Level) {
+		m[name] = level
+	})
+}
+
+// RemoveOverride removes the level override of the logger named name.
+func (c *LevelController) RemoveOverride(name string) {
+	c.updateOverrides(func(m map[string]slog.Level) {
+		delete(m, name)
+	})
+}
+
+// updateOverrides replaces the overrides with a copy updated by fn.
+func (c *LevelController) updateOverrides(fn func(m map[string]slog.Level)) {
+	for {
+		current := c.overrides.Load()
+		updated := make(map[string]slog.Level, len(*current)+1)
+		for k, v := range *current {
+			updated[k] = v
+		}
+		fn(updated)
+		if c.overrides.CompareAndSwap(current, &updated) {
+			return
+		}
+	}
+}
+
+// DebugFor activates the temporary debug mode for d, which lowers all the levels to [slog.LevelDebug]
+// and reverts automatically. if d is zero or negative, the mode is deactivated.
+func (c *LevelController) DebugFor(d time.Duration) {
+	if d <= 0 {
+		c.debugUntil.Store(0)
+		return
+	}
+	c.debugUntil.Store(c.now().Add(d).UnixNano())
+}
+
+// State returns the current state.
+func (c *LevelController) State() LevelState {
+	current := c.overrides.Load()
+	overrides := make(map[string]slog.Level, len(*current))
+	for k, v := range *current {
+		overrides[k] = v
+	}
+	s := LevelState{Level: c.global.Level(), Overrides: overrides}
+	if until := c.debugUntil.Load(); until != 0 && c.now().UnixNano() < until {
+		t := time.Unix(0, until)
+		s.DebugUntil = &t
+	}
+	return s
+}
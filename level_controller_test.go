@@ -0,0 +1,84 @@
+package altnrslog
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestLevelController_levelFor(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewLevelController(nil)
+	c.now = func() time.Time { return now }
+	c.SetLevel(slog.LevelWarn)
+	c.SetOverride("billing", slog.LevelError)
+
+	if got := c.levelFor(""); got != slog.LevelWarn {
+		t.Errorf("levelFor(\"\") = %v, want %v", got, slog.LevelWarn)
+	}
+	if got := c.levelFor("billing"); got != slog.LevelError {
+		t.Errorf("levelFor(billing) = %v, want %v", got, slog.LevelError)
+	}
+	if got := c.levelFor("shipping"); got != slog.LevelWarn {
+		t.Errorf("levelFor(shipping) = %v, want %v", got, slog.LevelWarn)
+	}
+
+	c.DebugFor(time.Minute)
+	if got := c.levelFor("billing"); got != slog.LevelDebug {
+		t.Errorf("levelFor(billing) in debug mode = %v, want %v", got, slog.LevelDebug)
+	}
+	if got := c.State().DebugUntil; got == nil || !got.Equal(now.Add(time.Minute)) {
+		t.Errorf("State().DebugUntil = %v, want %v", got, now.Add(time.Minute))
+	}
+
+	now = now.Add(time.Minute)
+	if got := c.Level(); got != slog.LevelWarn {
+		t.Errorf("Level() after debug mode = %v, want %v", got, slog.LevelWarn)
+	}
+	if got := c.State().DebugUntil; got != nil {
+		t.Errorf("State().DebugUntil after debug mode = %v, want nil", got)
+	}
+
+	c.RemoveOverride("billing")
+	if got := c.levelFor("billing"); got != slog.LevelWarn {
+		t.Errorf("levelFor(billing) after RemoveOverride = %v, want %v", got, slog.LevelWarn)
+	}
+}
+
+func TestTransactionalHandler_Enabled_WithLevelController(t *testing.T) {
+	c := NewLevelController(nil)
+	c.SetOverride("billing", slog.LevelDebug)
+	logger := slog.New(NewTransactionalHandler(nil, nil, WithInnerWriter(&mockWriter{}), WithLeveler(c)))
+	ctx := context.Background()
+
+	type test struct {
+		logger *slog.Logger
+		want   bool
+	}
+	tests := map[string]test{
+		"happy-path: global": {
+			logger: logger,
+			want:   false,
+		},
+		"happy-path: override": {
+			logger: logger.With(KeyLogger, "billing"),
+			want:   true,
+		},
+		"happy-path: override kept by attrs": {
+			logger: logger.With(KeyLogger, "billing").With("user", "foo"),
+			want:   true,
+		},
+		"happy-path: logger in group is not a name": {
+			logger: logger.WithGroup("request").With(KeyLogger, "billing"),
+			want:   false,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := tt.logger.Enabled(ctx, slog.LevelDebug); got != tt.want {
+				t.Errorf("Enabled(DEBUG) = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
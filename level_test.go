@@ -0,0 +1,194 @@
+package altnrslog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+)
+
+const (
+	testLevelTrace = slog.Level(-8)
+	testLevelFatal = slog.Level(12)
+)
+
+func TestProperties_levelName(t *testing.T) {
+	p := buildProperties([]HandlerOption{WithLevelMappings(map[slog.Level]LevelMapping{
+		testLevelTrace: {Name: "TRACE"},
+		testLevelFatal: {Name: "FATAL", Severity: "CRITICAL"},
+	})})
+	type want struct {
+		name     string
+		severity string
+	}
+	type test struct {
+		args slog.Level
+		want want
+	}
+	tests := map[string]test{
+		"happy-path: mapped name": {
+			args: testLevelTrace,
+			want: want{name: "TRACE", severity: "TRACE"},
+		},
+		"happy-path: mapped name and severity": {
+			args: testLevelFatal,
+			want: want{name: "FATAL", severity: "CRITICAL"},
+		},
+		"happy-path: not mapped": {
+			args: slog.LevelWarn + 1,
+			want: want{name: "WARN+1", severity: "WARN+1"},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := p.levelName(tt.args); got != tt.want.name {
+				t.Errorf("levelName() = %s, want %s", got, tt.want.name)
+			}
+			if got := p.severity(tt.args); got != tt.want.severity {
+				t.Errorf("severity() = %s, want %s", got, tt.want.severity)
+			}
+		})
+	}
+}
+
+func TestProperties_resolveSlogHandlerOptions(t *testing.T) {
+	opts := &slog.HandlerOptions{Level: slog.LevelDebug}
+	t.Run("happy-path: no mappings", func(t *testing.T) {
+		p := buildProperties([]HandlerOption{WithSlogHandlerSpecify(true, opts)})
+		if got := p.resolveSlogHandlerOptions(); got != opts {
+			t.Errorf("resolveSlogHandlerOptions() = %p, want %p", got, opts)
+		}
+	})
+	t.Run("happy-path: with mappings", func(t *testing.T) {
+		p := buildProperties([]HandlerOption{
+			WithSlogHandlerSpecify(true, opts),
+			WithLevelMappings(map[slog.Level]LevelMapping{testLevelTrace: {Name: "TRACE"}}),
+		})
+		got := p.resolveSlogHandlerOptions()
+		if got.Level != opts.Level {
+			t.Errorf("Level = %v, want %v", got.Level, opts.Level)
+		}
+		if opts.ReplaceAttr != nil {
+			t.Error("the specified options must not be modified")
+		}
+		a := got.ReplaceAttr(nil, slog.Any(slog.LevelKey, testLevelTrace))
+		if a.Value.String() != "TRACE" {
+			t.Errorf("ReplaceAttr() = %v, want %v", a.Value, "TRACE")
+		}
+		a = got.ReplaceAttr([]string{"group"}, slog.Any(slog.LevelKey, testLevelTrace))
+		if _, ok := a.Value.Any().(slog.Level); !ok {
+			t.Errorf("ReplaceAttr() in group = %v, want unchanged", a.Value)
+		}
+	})
+}
+
+func TestTransactionalHandler_Handle_WithLevelMappings(t *testing.T) {
+	buf := &bytes.Buffer{}
+	var severities []string
+	w := &testWriterFunc{}
+	h := NewTransactionalHandler(nil, nil,
+		WithInnerWriter(w),
+		WithSlogHandlerSpecify(true, &slog.HandlerOptions{Level: testLevelTrace}),
+		WithLogLevel(testLevelTrace),
+		WithLevelMappings(map[slog.Level]LevelMapping{
+			testLevelTrace: {Name: "TRACE", Severity: "DEBUG"},
+		}))
+	w.fn = func(p []byte) {
+		buf.Write(p)
+		severities = append(severities, h.writer.severity)
+	}
+	logger := slog.New(h)
+	logger.Log(context.Background(), testLevelTrace, "trace")
+	logger.Info("info")
+
+	lines := testHelper_JSONLines(t, buf)
+	if len(lines) != 2 {
+		t.Fatalf("logged %d records, want 2", len(lines))
+	}
+	if lines[0][slog.LevelKey] != "TRACE" {
+		t.Errorf("level = %v, want %v", lines[0][slog.LevelKey], "TRACE")
+	}
+	if lines[1][slog.LevelKey] != "INFO" {
+		t.Errorf("level = %v, want %v", lines[1][slog.LevelKey], "INFO")
+	}
+	if severities[0] != "DEBUG" || severities[1] != "INFO" {
+		t.Errorf("severities = %v, want %v", severities, []string{"DEBUG", "INFO"})
+	}
+}
+
+// testLogValuerFunc is a [slog.LogValuer] calling fn on resolution.
+type testLogValuerFunc func() slog.Value
+
+func (f testLogValuerFunc) LogValue() slog.Value {
+	return f()
+}
+
+func TestTransactionalHandler_Handle_LoggingDuringFormatting(t *testing.T) {
+	buf := &bytes.Buffer{}
+	h := NewTransactionalHandler(nil, nil, WithInnerWriter(buf), WithSlogHandlerSpecify(true, nil))
+	logger := slog.New(h).With(slog.String("foo", "bar"))
+	logger.Warn("outer", slog.Any("inner", testLogValuerFunc(func() slog.Value {
+		logger.Info("inner")
+		return slog.StringValue("resolved")
+	})))
+
+	lines := testHelper_JSONLines(t, buf)
+	if len(lines) != 2 {
+		t.Fatalf("logged %d records, want 2", len(lines))
+	}
+	if lines[0][slog.MessageKey] != "inner" || lines[1][slog.MessageKey] != "outer" {
+		t.Errorf("messages = %v, %v, want %v, %v", lines[0][slog.MessageKey], lines[1][slog.MessageKey], "inner", "outer")
+	}
+	if lines[0]["foo"] != "bar" {
+		t.Errorf("foo = %v, want %v", lines[0]["foo"], "bar")
+	}
+}
+
+func TestTransactionalHandler_Handle_Concurrent(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		lines int
+	)
+	w := &testWriterFunc{fn: func(p []byte) {
+		mu.Lock()
+		defer mu.Unlock()
+		lines += bytes.Count(p, []byte("\n"))
+	}}
+	logger := slog.New(NewTransactionalHandler(nil, nil, WithInnerWriter(w))).With(slog.String("foo", "bar"))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				logger.Info("msg", slog.Int("j", j))
+			}
+		}()
+	}
+	wg.Wait()
+	if lines != 800 {
+		t.Errorf("logged %d lines, want %d", lines, 800)
+	}
+}
+
+func TestTransactionalHandler_Enabled_WithLeveler(t *testing.T) {
+	lv := &slog.LevelVar{}
+	lv.Set(slog.LevelWarn)
+	h := NewTransactionalHandler(nil, nil, WithInnerWriter(&mockWriter{}), WithLogLevel(slog.LevelError), WithLeveler(lv))
+	ctx := context.Background()
+	if h.Enabled(ctx, slog.LevelInfo) {
+		t.Error("Enabled(INFO) = true, want false")
+	}
+	if !h.Enabled(ctx, slog.LevelWarn) {
+		t.Error("Enabled(WARN) = false, want true")
+	}
+	lv.Set(slog.LevelDebug)
+	if !h.Enabled(ctx, slog.LevelDebug) {
+		t.Error("Enabled(DEBUG) after Set = false, want true")
+	}
+	if got := h.Level(); got != slog.LevelDebug {
+		t.Errorf("Level() = %v, want %v", got, slog.LevelDebug)
+	}
+}
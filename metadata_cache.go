@@ -0,0 +1,71 @@
+package altnrslog
+
+import (
+	"log/slog"
+	"sync/atomic"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+// traceMetadataProvider is a [MetadataProvider] that can also provide the trace metadata alone.
+//
+// [*newrelic.Transaction] satisfies it.
+type traceMetadataProvider interface {
+	MetadataProvider
+	GetTraceMetadata() newrelic.TraceMetadata
+}
+
+// cachedMetadata is a snapshot of linking metadata and the [slog.Attr] converted from it.
+type cachedMetadata struct {
+	md newrelic.LinkingMetadata
+	// attrs is shared by all the records while the metadata is unchanged, so it must not be modified.
+	attrs []slog.Attr
+}
+
+// newCachedMetadata is constructor for cachedMetadata.
+func newCachedMetadata(md newrelic.LinkingMetadata) *cachedMetadata {
+	return &cachedMetadata{md: md, attrs: attrsFromMetadata(md)}
+}
+
+// metadataCache caches the linking metadata of a transaction.
+//
+// The entity metadata does not change during a transaction, so if the provider satisfies traceMetadataProvider,
+// only the trace metadata is fetched per record. The attributes are rebuilt only when the active span changes.
+// It is shared by the handlers derived by [TransactionalHandler.WithAttrs] and [TransactionalHandler.WithGroup].
+type metadataCache struct {
+	src     MetadataProvider
+	current atomic.Pointer[cachedMetadata]
+}
+
+// newMetadataCache is constructor for metadataCache.
+func newMetadataCache(src MetadataProvider) *metadataCache {
+	return &metadataCache{src: src}
+}
+
+// load returns the linking metadata of the active span, refreshing the cache if the span has changed.
+func (c *metadataCache) load() *cachedMetadata {
+	cached := c.current.Load()
+	if cached == nil {
+		cached = newCachedMetadata(c.src.GetLinkingMetadata())
+		c.current.Store(cached)
+		return cached
+	}
+	var md newrelic.LinkingMetadata
+	if src, ok := c.src.(traceMetadataProvider); ok {
+		tm := src.GetTraceMetadata()
+		if tm.TraceID == cached.md.TraceID && tm.SpanID == cached.md.SpanID {
+			return cached
+		}
+		md = cached.md
+		md.TraceID = tm.TraceID
+		md.SpanID = tm.SpanID
+	} else {
+		md = c.src.GetLinkingMetadata()
+		if md == cached.md {
+			return cached
+		}
+	}
+	cached = newCachedMetadata(md)
+	c.current.Store(cached)
+	return cached
+}
@@ -0,0 +1,169 @@
+package altnrslog
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+type fakeLinkingMetadataSource struct {
+	md                 newrelic.LinkingMetadata
+	linkingCalls       int
+	traceMetadataCalls int
+}
+
+func (f *fakeLinkingMetadataSource) GetLinkingMetadata() newrelic.LinkingMetadata {
+	f.linkingCalls++
+	return f.md
+}
+
+func (f *fakeLinkingMetadataSource) GetTraceMetadata() newrelic.TraceMetadata {
+	f.traceMetadataCalls++
+	return newrelic.TraceMetadata{TraceID: f.md.TraceID, SpanID: f.md.SpanID}
+}
+
+func Test_metadataCache_load(t *testing.T) {
+	src := &fakeLinkingMetadataSource{
+		md: newrelic.LinkingMetadata{
+			TraceID:    "trace-id",
+			SpanID:     "span-1",
+			EntityName: "entity-name",
+			EntityType: "entity-type",
+			EntityGUID: "entity-guid",
+			Hostname:   "hostname",
+		},
+	}
+	sut := newMetadataCache(src)
+
+	first := sut.load().attrs
+	if diff := cmp.Diff(first, attrsFromMetadata(src.md)); diff != "" {
+		t.Error(diff)
+	}
+	second := sut.load().attrs
+	if &first[0] != &second[0] {
+		t.Error("load() must reuse the cached attributes while the span is unchanged")
+	}
+
+	src.md.SpanID = "span-2"
+	third := sut.load().attrs
+	if diff := cmp.Diff(third, attrsFromMetadata(src.md)); diff != "" {
+		t.Error(diff)
+	}
+	if src.linkingCalls != 1 {
+		t.Errorf("GetLinkingMetadata() called %d times, want 1", src.linkingCalls)
+	}
+	if src.traceMetadataCalls != 2 {
+		t.Errorf("GetTraceMetadata() called %d times, want 2", src.traceMetadataCalls)
+	}
+}
+
+type fakeMetadataProvider struct {
+	md    newrelic.LinkingMetadata
+	calls int
+}
+
+func (f *fakeMetadataProvider) GetLinkingMetadata() newrelic.LinkingMetadata {
+	f.calls++
+	return f.md
+}
+
+func Test_metadataCache_load_WithoutTraceMetadata(t *testing.T) {
+	src := &fakeMetadataProvider{
+		md: newrelic.LinkingMetadata{TraceID: "trace-id", SpanID: "span-1"},
+	}
+	sut := newMetadataCache(src)
+
+	first := sut.load().attrs
+	second := sut.load().attrs
+	if &first[0] != &second[0] {
+		t.Error("load() must reuse the cached attributes while the metadata is unchanged")
+	}
+	src.md.SpanID = "span-2"
+	if diff := cmp.Diff(sut.load().attrs, attrsFromMetadata(src.md)); diff != "" {
+		t.Error(diff)
+	}
+	if src.calls != 3 {
+		t.Errorf("GetLinkingMetadata() called %d times, want 3", src.calls)
+	}
+}
+
+func TestHandlerFactory_New_WithMetadataProvider(t *testing.T) {
+	provider := &fakeMetadataProvider{
+		md: newrelic.LinkingMetadata{TraceID: "trace-id", SpanID: "span-id"},
+	}
+	tx := newrelic.Transaction{}
+	sut := NewHandlerFactory(nil, WithMetadataProvider(provider))
+	h := sut.New(&tx)
+	if h.metadata.src != provider {
+		t.Errorf("New().metadata.src = %v, want %v", h.metadata.src, provider)
+	}
+	if rebound := h.WithTransaction(&newrelic.Transaction{}); rebound.metadata.src != provider {
+		t.Errorf("WithTransaction().metadata.src = %v, want %v", rebound.metadata.src, provider)
+	}
+}
+
+func TestTransactionalHandler_WithAttrs_SharesMetadataCache(t *testing.T) {
+	app := newrelic.Application{}
+	tx := newrelic.Transaction{}
+	sut := NewTransactionalHandler(&app, &tx)
+	derived := sut.WithAttrs([]slog.Attr{slog.String("foo", "bar")}).WithGroup("baz").(*TransactionalHandler)
+	if derived.metadata != sut.metadata {
+		t.Error("WithAttrs() and WithGroup() must share the metadata cache")
+	}
+	if rebound := sut.WithTransaction(&newrelic.Transaction{}); rebound.metadata == sut.metadata {
+		t.Error("WithTransaction() must not share the metadata cache")
+	}
+}
+
+func benchmarkHelper_Transaction(b *testing.B) *newrelic.Transaction {
+	b.Helper()
+	app, err := newrelic.NewApplication(
+		newrelic.ConfigAppName("altnrslog-benchmark"),
+		newrelic.ConfigLicense("0123456789012345678901234567890123456789"),
+		newrelic.ConfigEnabled(false),
+	)
+	if err != nil {
+		b.Fatal(err)
+	}
+	tx := app.StartTransaction("benchmark")
+	b.Cleanup(tx.End)
+	return tx
+}
+
+func BenchmarkTransactionalHandler_Handle_UncachedMetadata(b *testing.B) {
+	tx := benchmarkHelper_Transaction(b)
+	h := &TransactionalHandler{
+		handler: slog.NewJSONHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelDebug}),
+		tx:      tx,
+		level:   slog.LevelDebug,
+	}
+	r := slog.NewRecord(time.Now(), slog.LevelDebug, "debug", 0)
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		h.Handle(ctx, r)
+	}
+}
+
+func BenchmarkTransactionalHandler_Handle_CachedMetadata(b *testing.B) {
+	tx := benchmarkHelper_Transaction(b)
+	h := &TransactionalHandler{
+		handler:  slog.NewJSONHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelDebug}),
+		tx:       tx,
+		level:    slog.LevelDebug,
+		metadata: newMetadataCache(tx),
+	}
+	r := slog.NewRecord(time.Now(), slog.LevelDebug, "debug", 0)
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		h.Handle(ctx, r)
+	}
+}
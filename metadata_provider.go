@@ -0,0 +1,135 @@
+package altnrslog
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// MetadataProvider provides the linking metadata to be added to log records.
+//
+// [*newrelic.Transaction] satisfies it.
+// For [*newrelic.Application] and [trace.SpanContext], use [ApplicationMetadataProvider] and [SpanContextMetadataProvider].
+type MetadataProvider interface {
+	GetLinkingMetadata() newrelic.LinkingMetadata
+}
+
+var _ traceMetadataProvider = (*newrelic.Transaction)(nil)
+
+const (
+	// minEntityGUIDRetryInterval is the interval of the first retry to get the entity GUID
+	// until the application has connected.
+	minEntityGUIDRetryInterval = time.Second
+	// maxEntityGUIDRetryInterval is the upper bound of the interval doubled on each retry.
+	maxEntityGUIDRetryInterval = time.Minute
+)
+
+// applicationMetadataProvider is a [MetadataProvider] for [newrelic.Application].
+type applicationMetadataProvider struct {
+	app   *newrelic.Application
+	now   func() time.Time
+	fetch func() newrelic.LinkingMetadata
+	// complete is the entity metadata including the entity GUID, which no longer needs to be fetched.
+	complete atomic.Pointer[newrelic.LinkingMetadata]
+
+	mu       sync.Mutex
+	partial  *newrelic.LinkingMetadata
+	retryAt  time.Time
+	interval time.Duration
+}
+
+// ApplicationMetadataProvider returns a [MetadataProvider] that provides the entity metadata of app, without trace metadata.
+//
+// The entity metadata is got from an ignored transaction and cached.
+// Until the application has connected, the entity GUID is unknown, so it is fetched again
+// with the interval doubled from 1 second up to 1 minute. If the agent is disabled, it is never fetched again.
+func ApplicationMetadataProvider(app *newrelic.Application) MetadataProvider {
+	p := &applicationMetadataProvider{app: app, now: time.Now}
+	p.fetch = p.fetchFromTransaction
+	return p
+}
+
+// GetLinkingMetadata See: [MetadataProvider.GetLinkingMetadata]
+func (p *applicationMetadataProvider) GetLinkingMetadata() newrelic.LinkingMetadata {
+	if md := p.complete.Load(); md != nil {
+		return *md
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if md := p.complete.Load(); md != nil {
+		return *md
+	}
+	now := p.now()
+	if p.partial != nil && now.Before(p.retryAt) {
+		return *p.partial
+	}
+	md := p.fetch()
+	if md.EntityGUID != "" || !p.agentEnabled() {
+		p.complete.Store(&md)
+		return md
+	}
+	p.interval = min(max(p.interval*2, minEntityGUIDRetryInterval), maxEntityGUIDRetryInterval)
+	p.partial = &md
+	p.retryAt = now.Add(p.interval)
+	return md
+}
+
+// fetchFromTransaction gets the entity metadata from an ignored transaction.
+func (p *applicationMetadataProvider) fetchFromTransaction() newrelic.LinkingMetadata {
+	tx := p.app.StartTransaction("altnrslog/ApplicationMetadataProvider")
+	md := tx.GetLinkingMetadata()
+	tx.Ignore()
+	md.TraceID = ""
+	md.SpanID = ""
+	return md
+}
+
+// agentEnabled reports whether the agent of the application is enabled, so that it can connect.
+func (p *applicationMetadataProvider) agentEnabled() bool {
+	cfg, ok := p.app.Config()
+	return !ok || cfg.Enabled
+}
+
+// spanContextMetadataProvider is a [MetadataProvider] for [trace.SpanContext].
+type spanContextMetadataProvider struct {
+	sc     trace.SpanContext
+	entity MetadataProvider
+}
+
+// SpanContextMetadataProvider returns a [MetadataProvider] that provides the trace ID and span ID of sc.
+// The entity metadata is got from entity, such as [ApplicationMetadataProvider], if not nil.
+func SpanContextMetadataProvider(sc trace.SpanContext, entity MetadataProvider) MetadataProvider {
+	return &spanContextMetadataProvider{sc: sc, entity: entity}
+}
+
+// GetLinkingMetadata See: [MetadataProvider.GetLinkingMetadata]
+func (p *spanContextMetadataProvider) GetLinkingMetadata() newrelic.LinkingMetadata {
+	var md newrelic.LinkingMetadata
+	if p.entity != nil {
+		md = p.entity.GetLinkingMetadata()
+	}
+	tm := p.GetTraceMetadata()
+	md.TraceID = tm.TraceID
+	md.SpanID = tm.SpanID
+	return md
+}
+
+// GetTraceMetadata returns the trace ID and span ID of the span context.
+// Empty string identifiers are returned if the span context is invalid.
+func (p *spanContextMetadataProvider) GetTraceMetadata() newrelic.TraceMetadata {
+	return traceMetadataFromSpanContext(p.sc)
+}
+
+// traceMetadataFromSpanContext converts [trace.SpanContext] to [newrelic.TraceMetadata].
+func traceMetadataFromSpanContext(sc trace.SpanContext) newrelic.TraceMetadata {
+	if !sc.IsValid() {
+		return newrelic.TraceMetadata{}
+	}
+	return newrelic.TraceMetadata{
+		TraceID: sc.TraceID().String(),
+		SpanID:  sc.SpanID().String(),
+	}
+}
@@ -0,0 +1,149 @@
+package altnrslog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestApplicationMetadataProvider(t *testing.T) {
+	app := testHelper_Application(t)
+	got := ApplicationMetadataProvider(app).GetLinkingMetadata()
+	if got.EntityName != "altnrslog-test" {
+		t.Errorf("EntityName = %s, want %s", got.EntityName, "altnrslog-test")
+	}
+	if got.TraceID != "" || got.SpanID != "" {
+		t.Errorf("trace metadata = %s/%s, want empty", got.TraceID, got.SpanID)
+	}
+}
+
+func TestApplicationMetadataProvider_ZeroApplication(t *testing.T) {
+	got := ApplicationMetadataProvider(&newrelic.Application{}).GetLinkingMetadata()
+	if diff := cmp.Diff(got, newrelic.LinkingMetadata{}); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestApplicationMetadataProvider_AgentDisabled(t *testing.T) {
+	sut := ApplicationMetadataProvider(testHelper_Application(t)).(*applicationMetadataProvider)
+	fetch, fetched := sut.fetch, 0
+	sut.fetch = func() newrelic.LinkingMetadata {
+		fetched++
+		return fetch()
+	}
+	for i := 0; i < 3; i++ {
+		if got := sut.GetLinkingMetadata(); got.EntityName != "altnrslog-test" {
+			t.Errorf("EntityName = %s, want %s", got.EntityName, "altnrslog-test")
+		}
+	}
+	if fetched != 1 {
+		t.Errorf("fetched %d times, want 1", fetched)
+	}
+}
+
+func TestApplicationMetadataProvider_RetryEntityGUID(t *testing.T) {
+	start := time.Unix(0, 0)
+	now := start
+	fetched := 0
+	sut := ApplicationMetadataProvider(&newrelic.Application{}).(*applicationMetadataProvider)
+	sut.now = func() time.Time { return now }
+	sut.fetch = func() newrelic.LinkingMetadata {
+		fetched++
+		md := newrelic.LinkingMetadata{EntityName: "app", Hostname: "host"}
+		if fetched == 3 {
+			md.EntityGUID = "guid"
+		}
+		return md
+	}
+
+	type test struct {
+		elapsed     time.Duration
+		wantFetched int
+		wantGUID    string
+	}
+	tests := []test{
+		{elapsed: 0, wantFetched: 1},
+		{elapsed: 500 * time.Millisecond, wantFetched: 1},
+		{elapsed: time.Second, wantFetched: 2},
+		{elapsed: 2 * time.Second, wantFetched: 2},
+		{elapsed: 3 * time.Second, wantFetched: 3, wantGUID: "guid"},
+		{elapsed: time.Hour, wantFetched: 3, wantGUID: "guid"},
+	}
+	for _, tt := range tests {
+		now = start.Add(tt.elapsed)
+		got := sut.GetLinkingMetadata()
+		if got.EntityName != "app" || got.Hostname != "host" {
+			t.Errorf("%v: entity metadata = %s/%s, want %s/%s", tt.elapsed, got.EntityName, got.Hostname, "app", "host")
+		}
+		if got.EntityGUID != tt.wantGUID {
+			t.Errorf("%v: EntityGUID = %s, want %s", tt.elapsed, got.EntityGUID, tt.wantGUID)
+		}
+		if fetched != tt.wantFetched {
+			t.Errorf("%v: fetched %d times, want %d", tt.elapsed, fetched, tt.wantFetched)
+		}
+	}
+}
+
+func TestSpanContextMetadataProvider(t *testing.T) {
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	sc := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID})
+
+	type args struct {
+		sc     trace.SpanContext
+		entity MetadataProvider
+	}
+	type test struct {
+		args args
+		want newrelic.LinkingMetadata
+	}
+	tests := map[string]test{
+		"happy-path: with entity": {
+			args: args{
+				sc: sc,
+				entity: &fakeMetadataProvider{md: newrelic.LinkingMetadata{
+					TraceID:    "ignored",
+					SpanID:     "ignored",
+					EntityName: "entity-name",
+					EntityType: "entity-type",
+					EntityGUID: "entity-guid",
+					Hostname:   "hostname",
+				}},
+			},
+			want: newrelic.LinkingMetadata{
+				TraceID:    "4bf92f3577b34da6a3ce929d0e0e4736",
+				SpanID:     "00f067aa0ba902b7",
+				EntityName: "entity-name",
+				EntityType: "entity-type",
+				EntityGUID: "entity-guid",
+				Hostname:   "hostname",
+			},
+		},
+		"happy-path: without entity": {
+			args: args{
+				sc: sc,
+			},
+			want: newrelic.LinkingMetadata{
+				TraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+				SpanID:  "00f067aa0ba902b7",
+			},
+		},
+		"unhappy-path: invalid span context": {
+			args: args{
+				sc: trace.SpanContext{},
+			},
+			want: newrelic.LinkingMetadata{},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := SpanContextMetadataProvider(tt.args.sc, tt.args.entity).GetLinkingMetadata()
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package altnrslog
+
+import (
+	"net/http"
+)
+
+// LoggerMiddleware returns the middleware that stores [*slog.Logger] with [*TransactionalHandler] created by factory
+// in the request context, bound to the [newrelic.Transaction] placed by [newrelic.WrapHandle] or other integrations.
+//
+// The logger can be got by [FromContext] or [LoggerOrDefault].
+func LoggerMiddleware(next http.Handler, factory *HandlerFactory) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, _ := ContextWithLogger(r.Context(), factory)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
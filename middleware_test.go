@@ -0,0 +1,28 @@
+package altnrslog
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+func TestLoggerMiddleware(t *testing.T) {
+	app := testHelper_Application(t)
+	factory := NewHandlerFactory(app, WithInnerWriter(io.Discard))
+	var got *newrelic.Transaction
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger, err := FromContext(r.Context())
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = logger.Handler().(*TransactionalHandler).Transaction()
+	})
+	_, sut := newrelic.WrapHandle(app, "/", LoggerMiddleware(next, factory))
+	sut.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if got == nil {
+		t.Error("logger not bound to the transaction placed by newrelic.WrapHandle")
+	}
+}
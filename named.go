@@ -0,0 +1,13 @@
+package altnrslog
+
+import (
+	"log/slog"
+)
+
+// Named returns a logger with the logger name, such as billing.invoice, added as the [KeyLogger] attribute.
+//
+// With [LevelController], the level of the logger is resolved from the overrides of the name and its ancestors.
+// The name must be added before [slog.Logger.WithGroup], otherwise it is only an attribute in the group.
+func Named(logger *slog.Logger, name string) *slog.Logger {
+	return logger.With(slog.String(KeyLogger, name))
+}
@@ -0,0 +1,72 @@
+package altnrslog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+)
+
+func TestNamed(t *testing.T) {
+	c := NewLevelController(nil)
+	c.SetOverride("billing", slog.LevelWarn)
+	c.SetOverride("billing.invoice", slog.LevelDebug)
+	logger := slog.New(NewTransactionalHandler(nil, nil, WithInnerWriter(&mockWriter{}), WithLeveler(c)))
+	ctx := context.Background()
+
+	type want struct {
+		debug bool
+		info  bool
+	}
+	type test struct {
+		args string
+		want want
+	}
+	tests := map[string]test{
+		"happy-path: exact": {
+			args: "billing.invoice",
+			want: want{debug: true, info: true},
+		},
+		"happy-path: descendant of exact": {
+			args: "billing.invoice.pdf",
+			want: want{debug: true, info: true},
+		},
+		"happy-path: parent": {
+			args: "billing.refund",
+			want: want{debug: false, info: false},
+		},
+		"happy-path: global": {
+			args: "shipping",
+			want: want{debug: false, info: true},
+		},
+		"happy-path: prefix is not a parent": {
+			args: "billingx",
+			want: want{debug: false, info: true},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			named := Named(logger, tt.args)
+			if got := named.Enabled(ctx, slog.LevelDebug); got != tt.want.debug {
+				t.Errorf("Enabled(DEBUG) = %v, want %v", got, tt.want.debug)
+			}
+			if got := named.Enabled(ctx, slog.LevelInfo); got != tt.want.info {
+				t.Errorf("Enabled(INFO) = %v, want %v", got, tt.want.info)
+			}
+		})
+	}
+}
+
+func TestNamed_Attr(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(NewTransactionalHandler(nil, nil, WithInnerWriter(buf), WithSlogHandlerSpecify(true, nil)))
+	Named(logger, "billing.invoice").Info("foo")
+
+	lines := testHelper_JSONLines(t, buf)
+	if len(lines) != 1 {
+		t.Fatalf("logged %d records, want 1", len(lines))
+	}
+	if got := lines[0][KeyLogger]; got != "billing.invoice" {
+		t.Errorf("%s = %v, want %v", KeyLogger, got, "billing.invoice")
+	}
+}
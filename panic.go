@@ -0,0 +1,131 @@
+package altnrslog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+const (
+	// KeyPanic is the attribute key of the recovered value.
+	KeyPanic = "panic"
+	// KeyStack is the attribute key of the stack trace of the panicked goroutine.
+	KeyStack = "stack"
+	// KeyGoroutineID is the attribute key of the ID of the panicked goroutine.
+	KeyGoroutineID = "goroutine.id"
+)
+
+// ErrPanicked is returned or recorded when a panic is recovered.
+var ErrPanicked = errors.New("panicked")
+
+// RecoverProperties is an options for recovering panics.
+type RecoverProperties struct {
+	repanic bool
+}
+
+// RecoverOption is a functional option for recovering panics.
+type RecoverOption func(*RecoverProperties)
+
+// WithRepanic specifies whether to panic again with the recovered value after logging it.
+func WithRepanic(repanic bool) RecoverOption {
+	return func(p *RecoverProperties) {
+		p.repanic = repanic
+	}
+}
+
+// buildRecoverProperties creates a new RecoverProperties with the given options.
+func buildRecoverProperties(options []RecoverOption) (props *RecoverProperties) {
+	props = &RecoverProperties{}
+	for _, o := range options {
+		o(props)
+	}
+	return
+}
+
+// RecoverAndLog recovers a panic, logs it at error level with the stack trace and the goroutine ID
+// through [*slog.Logger] stored in ctx, and records it with [newrelic.Transaction.NoticeError].
+// It must be called directly by defer.
+//
+//	defer altnrslog.RecoverAndLog(ctx)
+//
+// If ctx has no [*slog.Logger] with [*TransactionalHandler], [slog.Default] is used.
+func RecoverAndLog(ctx context.Context, options ...RecoverOption) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	props := buildRecoverProperties(options)
+	notePanic(ctx, r, debug.Stack())
+	if props.repanic {
+		panic(r)
+	}
+}
+
+// RecoverMiddleware returns the middleware that recovers panics in next like [RecoverAndLog],
+// and responds with 500 Internal Server Error unless [WithRepanic] is specified.
+//
+// [http.ErrAbortHandler] is re-panicked without logging.
+func RecoverMiddleware(next http.Handler, options ...RecoverOption) http.Handler {
+	props := buildRecoverProperties(options)
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+			if r == http.ErrAbortHandler {
+				panic(r)
+			}
+			notePanic(req.Context(), r, debug.Stack())
+			if props.repanic {
+				panic(r)
+			}
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}()
+		next.ServeHTTP(w, req)
+	})
+}
+
+// notePanic logs the recovered value r and records it to the transaction in ctx, and returns it as an error.
+func notePanic(ctx context.Context, r any, stack []byte) error {
+	err := fmt.Errorf("%w: %v", ErrPanicked, r)
+
+	logger, lerr := FromContext(ctx)
+	tx := newrelic.FromContext(ctx)
+	if lerr != nil {
+		logger = slog.Default()
+	} else if htx := logger.Handler().(*TransactionalHandler).Transaction(); htx != nil {
+		tx = htx
+	}
+	logger.ErrorContext(ctx, "panic recovered",
+		slog.Any(KeyPanic, r),
+		slog.String(KeyStack, string(stack)),
+		slog.Int64(KeyGoroutineID, goroutineID(stack)))
+	tx.NoticeError(err)
+	return err
+}
+
+// goroutineID parses the goroutine ID from the header of the stack trace, such as "goroutine 1 [running]:".
+// It returns 0 if the header cannot be parsed.
+func goroutineID(stack []byte) int64 {
+	stack, ok := bytes.CutPrefix(stack, []byte("goroutine "))
+	if !ok {
+		return 0
+	}
+	i := bytes.IndexByte(stack, ' ')
+	if i < 0 {
+		return 0
+	}
+	id, err := strconv.ParseInt(string(stack[:i]), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return id
+}
@@ -0,0 +1,147 @@
+package altnrslog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+func Test_goroutineID(t *testing.T) {
+	type test struct {
+		args []byte
+		want int64
+	}
+	tests := map[string]test{
+		"happy-path": {
+			args: []byte("goroutine 42 [running]:\nmain.main()"),
+			want: 42,
+		},
+		"unhappy-path: no header": {
+			args: []byte("main.main()"),
+		},
+		"unhappy-path: invalid id": {
+			args: []byte("goroutine foo [running]:"),
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := goroutineID(tt.args); got != tt.want {
+				t.Errorf("goroutineID() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func testHelper_LoggerContext(t *testing.T, buf *bytes.Buffer) context.Context {
+	t.Helper()
+	app := testHelper_Application(t)
+	tx := app.StartTransaction("test")
+	t.Cleanup(tx.End)
+	ctx := newrelic.NewContext(context.Background(), tx)
+	ctx, err := StoreToContext(ctx, slog.New(NewTransactionalHandler(app, tx, testHelper_BufferOption(buf))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ctx
+}
+
+func testHelper_AssertPanicLogged(t *testing.T, buf *bytes.Buffer, value string) {
+	t.Helper()
+	lines := testHelper_JSONLines(t, buf)
+	if len(lines) != 1 {
+		t.Fatalf("logged %d records, want 1", len(lines))
+	}
+	l := lines[0]
+	if l[slog.LevelKey] != slog.LevelError.String() {
+		t.Errorf("level = %v, want %v", l[slog.LevelKey], slog.LevelError)
+	}
+	if l[KeyPanic] != value {
+		t.Errorf("%s = %v, want %v", KeyPanic, l[KeyPanic], value)
+	}
+	if stack, _ := l[KeyStack].(string); !strings.HasPrefix(stack, "goroutine ") {
+		t.Errorf("%s = %v, want stack trace", KeyStack, l[KeyStack])
+	}
+	if id, _ := l[KeyGoroutineID].(float64); id <= 0 {
+		t.Errorf("%s = %v, want positive", KeyGoroutineID, l[KeyGoroutineID])
+	}
+}
+
+func TestRecoverAndLog(t *testing.T) {
+	buf := &bytes.Buffer{}
+	ctx := testHelper_LoggerContext(t, buf)
+	func() {
+		defer RecoverAndLog(ctx)
+		panic("boom")
+	}()
+	testHelper_AssertPanicLogged(t, buf, "boom")
+}
+
+func TestRecoverAndLog_WithRepanic(t *testing.T) {
+	buf := &bytes.Buffer{}
+	ctx := testHelper_LoggerContext(t, buf)
+	var got any
+	func() {
+		defer func() {
+			got = recover()
+		}()
+		defer RecoverAndLog(ctx, WithRepanic(true))
+		panic("boom")
+	}()
+	if got != "boom" {
+		t.Errorf("recover() = %v, want %v", got, "boom")
+	}
+	testHelper_AssertPanicLogged(t, buf, "boom")
+}
+
+func TestRecoverAndLog_NoPanic(t *testing.T) {
+	buf := &bytes.Buffer{}
+	ctx := testHelper_LoggerContext(t, buf)
+	func() {
+		defer RecoverAndLog(ctx)
+	}()
+	if buf.Len() != 0 {
+		t.Errorf("logged %q, want nothing", buf.String())
+	}
+}
+
+func TestRecoverMiddleware(t *testing.T) {
+	buf := &bytes.Buffer{}
+	ctx := testHelper_LoggerContext(t, buf)
+	handler := RecoverMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+	testHelper_AssertPanicLogged(t, buf, "boom")
+}
+
+func TestRecoverMiddleware_ErrAbortHandler(t *testing.T) {
+	buf := &bytes.Buffer{}
+	ctx := testHelper_LoggerContext(t, buf)
+	handler := RecoverMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	var got any
+	func() {
+		defer func() {
+			got = recover()
+		}()
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx))
+	}()
+	if got != http.ErrAbortHandler {
+		t.Errorf("recover() = %v, want %v", got, http.ErrAbortHandler)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("logged %q, want nothing", buf.String())
+	}
+}
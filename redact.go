@@ -0,0 +1,88 @@
+package altnrslog
+
+import (
+	"log/slog"
+)
+
+// RedactedValue is the value that replaces the values of the attributes redacted by [WithRedactKeys].
+const RedactedValue = "[REDACTED]"
+
+// WithRedactKeys specifies the keys of the attributes whose values are replaced by [RedactedValue],
+// such as password or authorization.
+//
+// The keys are matched exactly, including the keys of the attributes in groups.
+// The attributes are redacted before being passed to the inner handler, so they are never forwarded to New Relic.
+func WithRedactKeys(keys ...string) HandlerOption {
+	return func(p *Properties) {
+		p.redactKeys = redactKeySet(keys)
+	}
+}
+
+// redactKeySet returns the set of keys, or nil if keys is empty.
+func redactKeySet(keys []string) map[string]struct{} {
+	if len(keys) == 0 {
+		return nil
+	}
+	set := make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		set[k] = struct{}{}
+	}
+	return set
+}
+
+// redactAttrs returns attrs with the values of the keys redacted, and reports whether any value is redacted.
+// If no value is redacted, attrs is returned as is.
+func redactAttrs(keys map[string]struct{}, attrs []slog.Attr) ([]slog.Attr, bool) {
+	var redacted []slog.Attr
+	for i, a := range attrs {
+		ra, ok := redactAttr(keys, a)
+		if !ok {
+			if redacted != nil {
+				redacted = append(redacted, a)
+			}
+			continue
+		}
+		if redacted == nil {
+			redacted = make([]slog.Attr, i, len(attrs))
+			copy(redacted, attrs[:i])
+		}
+		redacted = append(redacted, ra)
+	}
+	if redacted == nil {
+		return attrs, false
+	}
+	return redacted, true
+}
+
+// redactAttr returns a with the values of the keys redacted, and reports whether any value is redacted.
+func redactAttr(keys map[string]struct{}, a slog.Attr) (slog.Attr, bool) {
+	if _, ok := keys[a.Key]; ok {
+		return slog.String(a.Key, RedactedValue), true
+	}
+	v := a.Value.Resolve()
+	if v.Kind() != slog.KindGroup {
+		return a, false
+	}
+	group, ok := redactAttrs(keys, v.Group())
+	if !ok {
+		return a, false
+	}
+	return slog.Attr{Key: a.Key, Value: slog.GroupValue(group...)}, true
+}
+
+// redactRecord returns a copy of r with the values of the keys redacted.
+// If no value is redacted, r is returned as is.
+func redactRecord(keys map[string]struct{}, r slog.Record) slog.Record {
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	redacted, ok := redactAttrs(keys, attrs)
+	if !ok {
+		return r
+	}
+	cleaned := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	cleaned.AddAttrs(redacted...)
+	return cleaned
+}
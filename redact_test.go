@@ -0,0 +1,80 @@
+package altnrslog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_redactAttrs(t *testing.T) {
+	keys := map[string]struct{}{"password": {}, "token": {}}
+	type want struct {
+		attrs    []slog.Attr
+		redacted bool
+	}
+	type test struct {
+		args []slog.Attr
+		want want
+	}
+	tests := map[string]test{
+		"happy-path: not redacted": {
+			args: []slog.Attr{slog.String("user", "foo")},
+			want: want{attrs: []slog.Attr{slog.String("user", "foo")}},
+		},
+		"happy-path: redacted": {
+			args: []slog.Attr{slog.String("user", "foo"), slog.String("password", "bar"), slog.Int("n", 1)},
+			want: want{
+				attrs:    []slog.Attr{slog.String("user", "foo"), slog.String("password", RedactedValue), slog.Int("n", 1)},
+				redacted: true,
+			},
+		},
+		"happy-path: redacted in group": {
+			args: []slog.Attr{slog.Group("auth", slog.String("user", "foo"), slog.String("token", "bar"))},
+			want: want{
+				attrs:    []slog.Attr{slog.Group("auth", slog.String("user", "foo"), slog.String("token", RedactedValue))},
+				redacted: true,
+			},
+		},
+		"happy-path: group redacted as a whole": {
+			args: []slog.Attr{slog.Group("token", slog.String("value", "bar"))},
+			want: want{
+				attrs:    []slog.Attr{slog.String("token", RedactedValue)},
+				redacted: true,
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, redacted := redactAttrs(keys, tt.args)
+			if redacted != tt.want.redacted {
+				t.Errorf("redacted = %v, want %v", redacted, tt.want.redacted)
+			}
+			if diff := cmp.Diff(got, tt.want.attrs, cmp.Comparer(func(x, y slog.Value) bool { return x.Equal(y) })); diff != "" {
+				t.Errorf("(-got, +want)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTransactionalHandler_WithRedactKeys(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(NewTransactionalHandler(nil, nil,
+		WithInnerWriter(buf),
+		WithSlogHandlerSpecify(true, nil),
+		WithRedactKeys("password", "authorization")))
+	logger = logger.With(slog.String("authorization", "Bearer foo"))
+	logger.InfoContext(context.Background(), "login", slog.String("user", "bar"), slog.String("password", "baz"))
+
+	lines := testHelper_JSONLines(t, buf)
+	if len(lines) != 1 {
+		t.Fatalf("logged %d records, want 1", len(lines))
+	}
+	for k, want := range map[string]string{"authorization": RedactedValue, "password": RedactedValue, "user": "bar"} {
+		if lines[0][k] != want {
+			t.Errorf("%s = %v, want %v", k, lines[0][k], want)
+		}
+	}
+}
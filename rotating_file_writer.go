@@ -0,0 +1,365 @@
+package altnrslog
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat is the layout of the timestamp embedded in the name of rotated files.
+const backupTimeFormat = "20060102T150405.000"
+
+// compressSuffix is the suffix of rotated files compressed with gzip.
+const compressSuffix = ".gz"
+
+// ErrWriterClosed is returned when writing to a closed [RotatingFileWriter].
+var ErrWriterClosed = errors.New("writer closed")
+
+// RotatingFileWriter is an [io.WriteCloser] that writes to a file and rotates it by size and/or time.
+//
+// It is safe for concurrent use, so it can be passed to [WithInnerWriter] and shared by any number of [TransactionalHandler].
+//
+// The rotated files are compressed and removed in the background, so that writing is not blocked.
+// The errors in the background are returned by [RotatingFileWriter.Close].
+type RotatingFileWriter struct {
+	mu       sync.Mutex
+	filename string
+	props    *RotatingFileProperties
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	closed   bool
+	now      func() time.Time
+	// compressor compresses the rotated file, replaced in tests.
+	compressor func(name string) error
+
+	// cleanupMu serializes the compression and the removal of the rotated files.
+	cleanupMu   sync.Mutex
+	cleanups    sync.WaitGroup
+	cleanupErrs []error
+}
+
+// RotatingFileProperties is an options for creating a new [RotatingFileWriter].
+type RotatingFileProperties struct {
+	maxSize          int64
+	rotationInterval time.Duration
+	maxBackups       int
+	compress         bool
+}
+
+// RotatingFileOption is a functional option for creating a new [RotatingFileWriter].
+type RotatingFileOption func(*RotatingFileProperties)
+
+// WithMaxSize specifies the maximum size in bytes of the file before it gets rotated.
+// if not specified or zero, the file will not be rotated by size.
+func WithMaxSize(size int64) RotatingFileOption {
+	return func(p *RotatingFileProperties) {
+		p.maxSize = size
+	}
+}
+
+// WithRotationInterval specifies the interval at which the file gets rotated.
+// if not specified or zero, the file will not be rotated by time.
+func WithRotationInterval(interval time.Duration) RotatingFileOption {
+	return func(p *RotatingFileProperties) {
+		p.rotationInterval = interval
+	}
+}
+
+// WithMaxBackups specifies the maximum number of rotated files to retain.
+// if not specified or zero, all rotated files will be retained.
+func WithMaxBackups(n int) RotatingFileOption {
+	return func(p *RotatingFileProperties) {
+		p.maxBackups = n
+	}
+}
+
+// WithCompress specifies whether to compress rotated files with gzip.
+func WithCompress(compress bool) RotatingFileOption {
+	return func(p *RotatingFileProperties) {
+		p.compress = compress
+	}
+}
+
+// buildRotatingFileProperties creates a new RotatingFileProperties with the given options.
+func buildRotatingFileProperties(options []RotatingFileOption) (props *RotatingFileProperties) {
+	props = &RotatingFileProperties{}
+	for _, o := range options {
+		o(props)
+	}
+	return
+}
+
+// NewRotatingFileWriter is constructor for [RotatingFileWriter].
+// The file will be created if it does not exist, otherwise appended to.
+func NewRotatingFileWriter(filename string, options ...RotatingFileOption) (*RotatingFileWriter, error) {
+	w := &RotatingFileWriter{
+		filename:   filename,
+		props:      buildRotatingFileProperties(options),
+		now:        time.Now,
+		compressor: compressFile,
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write writes p to the file, rotating it beforehand if the size or time limit would be exceeded.
+func (w *RotatingFileWriter) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, ErrWriterClosed
+	}
+	if w.file == nil {
+		if err := w.open(); err != nil {
+			return 0, err
+		}
+	}
+	if w.shouldRotate(int64(len(p))) {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err = w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Rotate closes the current file, renames it as a backup and opens a new file.
+func (w *RotatingFileWriter) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return ErrWriterClosed
+	}
+	return w.rotate()
+}
+
+// Close closes the current file, and waits for the rotated files to be compressed and removed.
+// The errors in compressing and removing them are returned as well.
+func (w *RotatingFileWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	var err error
+	if w.file != nil {
+		err = w.file.Close()
+	}
+	w.mu.Unlock()
+
+	w.wait()
+	w.cleanupMu.Lock()
+	defer w.cleanupMu.Unlock()
+	return errors.Join(append([]error{err}, w.cleanupErrs...)...)
+}
+
+// shouldRotate reports whether the file must be rotated before writing n bytes.
+func (w *RotatingFileWriter) shouldRotate(n int64) bool {
+	if w.props.maxSize > 0 && w.size > 0 && w.size+n > w.props.maxSize {
+		return true
+	}
+	if w.props.rotationInterval > 0 && !w.now().Before(w.openedAt.Add(w.props.rotationInterval)) {
+		return true
+	}
+	return false
+}
+
+// open opens the file for appending, creating it and its directory if necessary.
+func (w *RotatingFileWriter) open() error {
+	if err := os.MkdirAll(filepath.Dir(w.filename), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(w.filename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	w.file = f
+	w.size = info.Size()
+	w.openedAt = w.now()
+	return nil
+}
+
+// rotate must be called with w.mu held.
+func (w *RotatingFileWriter) rotate() error {
+	if w.file != nil {
+		if err := w.file.Close(); err != nil {
+			return err
+		}
+		w.file = nil
+	}
+	backup := w.backupName()
+	if err := os.Rename(w.filename, backup); err != nil {
+		return err
+	}
+	if err := w.open(); err != nil {
+		return err
+	}
+	w.cleanups.Add(1)
+	go w.cleanUp(backup)
+	return nil
+}
+
+// cleanUp compresses the rotated file if necessary and removes the old backups.
+// The errors are kept to be returned by [RotatingFileWriter.Close], instead of failing the write that rotated the file.
+func (w *RotatingFileWriter) cleanUp(backup string) {
+	defer w.cleanups.Done()
+	w.cleanupMu.Lock()
+	defer w.cleanupMu.Unlock()
+
+	if w.props.compress {
+		if err := w.compressor(backup); err != nil {
+			w.cleanupErrs = append(w.cleanupErrs, err)
+		}
+	}
+	if err := w.removeOldBackups(); err != nil {
+		w.cleanupErrs = append(w.cleanupErrs, err)
+	}
+}
+
+// wait waits for the rotated files to be compressed and removed.
+func (w *RotatingFileWriter) wait() {
+	w.cleanups.Wait()
+}
+
+// backupName returns a name for the rotated file that does not collide with existing backups.
+func (w *RotatingFileWriter) backupName() string {
+	dir, prefix, ext := w.nameParts()
+	stamp := w.now().Format(backupTimeFormat)
+	name := filepath.Join(dir, fmt.Sprintf("%s-%s%s", prefix, stamp, ext))
+	for i := 1; fileExists(name) || fileExists(name+compressSuffix); i++ {
+		name = filepath.Join(dir, fmt.Sprintf("%s-%s.%d%s", prefix, stamp, i, ext))
+	}
+	return name
+}
+
+// nameParts splits the filename into directory, base name without extension and extension.
+func (w *RotatingFileWriter) nameParts() (dir, prefix, ext string) {
+	dir = filepath.Dir(w.filename)
+	base := filepath.Base(w.filename)
+	ext = filepath.Ext(base)
+	prefix = strings.TrimSuffix(base, ext)
+	return
+}
+
+// backups returns the rotated files of w, oldest first.
+func (w *RotatingFileWriter) backups() ([]string, error) {
+	dir, prefix, ext := w.nameParts()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	type backup struct {
+		name string
+		time time.Time
+		seq  int
+	}
+	var found []backup
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		name := strings.TrimSuffix(e.Name(), compressSuffix)
+		if !strings.HasPrefix(name, prefix+"-") || !strings.HasSuffix(name, ext) {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, prefix+"-"), ext)
+		if len(stamp) < len(backupTimeFormat) {
+			continue
+		}
+		t, err := time.Parse(backupTimeFormat, stamp[:len(backupTimeFormat)])
+		if err != nil {
+			continue
+		}
+		var seq int
+		if rest := stamp[len(backupTimeFormat):]; rest != "" {
+			if _, err := fmt.Sscanf(rest, ".%d", &seq); err != nil {
+				continue
+			}
+		}
+		found = append(found, backup{name: filepath.Join(dir, e.Name()), time: t, seq: seq})
+	}
+	sort.Slice(found, func(i, j int) bool {
+		if !found[i].time.Equal(found[j].time) {
+			return found[i].time.Before(found[j].time)
+		}
+		return found[i].seq < found[j].seq
+	})
+	backups := make([]string, 0, len(found))
+	for _, b := range found {
+		backups = append(backups, b.name)
+	}
+	return backups, nil
+}
+
+// removeOldBackups removes the oldest rotated files exceeding the maximum number of backups.
+func (w *RotatingFileWriter) removeOldBackups() error {
+	if w.props.maxBackups <= 0 {
+		return nil
+	}
+	backups, err := w.backups()
+	if err != nil {
+		return err
+	}
+	if len(backups) <= w.props.maxBackups {
+		return nil
+	}
+	var errs []error
+	for _, b := range backups[:len(backups)-w.props.maxBackups] {
+		errs = append(errs, os.Remove(b))
+	}
+	return errors.Join(errs...)
+}
+
+// compressFile compresses the file with gzip and removes the original.
+func compressFile(name string) (err error) {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(name+compressSuffix, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := dst.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	gw := gzip.NewWriter(dst)
+	if _, err := io.Copy(gw, src); err != nil {
+		return err
+	}
+	if err := gw.Close(); err != nil {
+		return err
+	}
+	src.Close()
+	return os.Remove(name)
+}
+
+// fileExists reports whether the named file exists.
+func fileExists(name string) bool {
+	_, err := os.Stat(name)
+	return err == nil
+}
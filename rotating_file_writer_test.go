@@ -0,0 +1,282 @@
+package altnrslog
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_buildRotatingFileProperties(t *testing.T) {
+	type test struct {
+		args []RotatingFileOption
+		want RotatingFileProperties
+	}
+	tests := map[string]test{
+		"happy-path: default": {
+			want: RotatingFileProperties{},
+		},
+		"happy-path: all options": {
+			args: []RotatingFileOption{
+				WithMaxSize(1024),
+				WithRotationInterval(time.Hour),
+				WithMaxBackups(3),
+				WithCompress(true),
+			},
+			want: RotatingFileProperties{
+				maxSize:          1024,
+				rotationInterval: time.Hour,
+				maxBackups:       3,
+				compress:         true,
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := buildRotatingFileProperties(tt.args)
+			if *got != tt.want {
+				t.Errorf("buildRotatingFileProperties() = %v, want %v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRotatingFileWriter_Write(t *testing.T) {
+	type args struct {
+		options []RotatingFileOption
+		writes  []string
+	}
+	type want struct {
+		current string
+		backups []string
+	}
+	type test struct {
+		args args
+		want want
+	}
+	tests := map[string]test{
+		"happy-path: no rotation": {
+			args: args{
+				writes: []string{"foo\n", "bar\n"},
+			},
+			want: want{
+				current: "foo\nbar\n",
+			},
+		},
+		"happy-path: rotate by size": {
+			args: args{
+				options: []RotatingFileOption{WithMaxSize(8)},
+				writes:  []string{"foo\n", "bar\n", "baz\n"},
+			},
+			want: want{
+				current: "baz\n",
+				backups: []string{"foo\nbar\n"},
+			},
+		},
+		"happy-path: rotate by size with max backups": {
+			args: args{
+				options: []RotatingFileOption{WithMaxSize(4), WithMaxBackups(2)},
+				writes:  []string{"foo\n", "bar\n", "baz\n", "qux\n"},
+			},
+			want: want{
+				current: "qux\n",
+				backups: []string{"bar\n", "baz\n"},
+			},
+		},
+		"happy-path: record larger than max size": {
+			args: args{
+				options: []RotatingFileOption{WithMaxSize(2)},
+				writes:  []string{"foo\n"},
+			},
+			want: want{
+				current: "foo\n",
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "app.log")
+			w, err := NewRotatingFileWriter(filename, tt.args.options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer w.Close()
+			for _, s := range tt.args.writes {
+				if _, err := w.Write([]byte(s)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			w.wait()
+			if got := testHelper_ReadFile(t, filename); got != tt.want.current {
+				t.Errorf("current = %q, want %q", got, tt.want.current)
+			}
+			backups, err := w.backups()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(backups) != len(tt.want.backups) {
+				t.Fatalf("len(backups) = %d, want %d", len(backups), len(tt.want.backups))
+			}
+			for i, b := range backups {
+				if got := testHelper_ReadFile(t, b); got != tt.want.backups[i] {
+					t.Errorf("backups[%d] = %q, want %q", i, got, tt.want.backups[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRotatingFileWriter_Write_RotateByInterval(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "app.log")
+	w, err := NewRotatingFileWriter(filename, WithRotationInterval(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	w.now = func() time.Time { return now }
+	w.openedAt = now
+
+	w.Write([]byte("foo\n"))
+	now = now.Add(30 * time.Minute)
+	w.Write([]byte("bar\n"))
+	now = now.Add(30 * time.Minute)
+	w.Write([]byte("baz\n"))
+
+	if got := testHelper_ReadFile(t, filename); got != "baz\n" {
+		t.Errorf("current = %q, want %q", got, "baz\n")
+	}
+	backup := filepath.Join(filepath.Dir(filename), "app-20240101T010000.000.log")
+	if got := testHelper_ReadFile(t, backup); got != "foo\nbar\n" {
+		t.Errorf("backup = %q, want %q", got, "foo\nbar\n")
+	}
+}
+
+func TestRotatingFileWriter_Rotate_Compress(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "app.log")
+	w, err := NewRotatingFileWriter(filename, WithCompress(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	w.Write([]byte("foo\n"))
+	if err := w.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	w.wait()
+	backups, err := w.backups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 1 || !strings.HasSuffix(backups[0], compressSuffix) {
+		t.Fatalf("backups = %v, want one gzip file", backups)
+	}
+	f, err := os.Open(backups[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := io.ReadAll(gr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "foo\n" {
+		t.Errorf("decompressed = %q, want %q", b, "foo\n")
+	}
+}
+
+func TestRotatingFileWriter_Write_CompressInBackground(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "app.log")
+	w, err := NewRotatingFileWriter(filename, WithMaxSize(4), WithCompress(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	errCompress := errors.New("compress failed")
+	release := make(chan struct{})
+	w.compressor = func(string) error {
+		<-release
+		return errCompress
+	}
+
+	for _, s := range []string{"foo\n", "bar\n"} {
+		if _, err := w.Write([]byte(s)); err != nil {
+			t.Fatalf("Write() = %v, want nil while compressing", err)
+		}
+	}
+	if got := testHelper_ReadFile(t, filename); got != "bar\n" {
+		t.Errorf("current = %q, want %q", got, "bar\n")
+	}
+	close(release)
+	if err := w.Close(); !errors.Is(err, errCompress) {
+		t.Errorf("Close() = %v, want %v", err, errCompress)
+	}
+}
+
+func TestRotatingFileWriter_Write_Concurrent(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "app.log")
+	w, err := NewRotatingFileWriter(filename, WithMaxSize(64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	const goroutines, writes = 8, 100
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < writes; j++ {
+				if _, err := w.Write([]byte("0123456789\n")); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	w.wait()
+
+	backups, err := w.backups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var total int
+	for _, name := range append(backups, filename) {
+		total += strings.Count(testHelper_ReadFile(t, name), "0123456789\n")
+	}
+	if total != goroutines*writes {
+		t.Errorf("total lines = %d, want %d", total, goroutines*writes)
+	}
+}
+
+func TestRotatingFileWriter_Write_Closed(t *testing.T) {
+	w, err := NewRotatingFileWriter(filepath.Join(t.TempDir(), "app.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	if _, err := w.Write([]byte("foo\n")); !errors.Is(err, ErrWriterClosed) {
+		t.Errorf("Write() error = %v, want %v", err, ErrWriterClosed)
+	}
+}
+
+func testHelper_ReadFile(t *testing.T, name string) string {
+	t.Helper()
+	b, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
@@ -0,0 +1,36 @@
+package altnrslog
+
+import (
+	"log/slog"
+	"math/rand"
+)
+
+// sampling keeps a fraction of the records at or below a level.
+type sampling struct {
+	rate  float64
+	level slog.Level
+}
+
+// WithSampling specifies that only the fraction rate of the records at or below level are handled,
+// such as 0.1 of the records at or below [slog.LevelInfo]. The records above level are always handled.
+//
+// rate is clamped to [0, 1].
+func WithSampling(rate float64, level slog.Level) HandlerOption {
+	return func(p *Properties) {
+		p.sampling = newSampling(rate, level)
+	}
+}
+
+// newSampling is constructor for sampling.
+func newSampling(rate float64, level slog.Level) *sampling {
+	return &sampling{rate: min(max(rate, 0), 1), level: level}
+}
+
+// keep reports whether a record at level is handled.
+// A nil sampling keeps all the records.
+func (s *sampling) keep(level slog.Level) bool {
+	if s == nil || level > s.level || s.rate >= 1 {
+		return true
+	}
+	return s.rate > 0 && rand.Float64() < s.rate
+}
@@ -0,0 +1,64 @@
+package altnrslog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+)
+
+func Test_sampling_keep(t *testing.T) {
+	type args struct {
+		sampling *sampling
+		level    slog.Level
+	}
+	type test struct {
+		args args
+		want bool
+	}
+	tests := map[string]test{
+		"happy-path: nil": {
+			args: args{level: slog.LevelDebug},
+			want: true,
+		},
+		"happy-path: above level": {
+			args: args{sampling: newSampling(0, slog.LevelInfo), level: slog.LevelWarn},
+			want: true,
+		},
+		"happy-path: rate 0": {
+			args: args{sampling: newSampling(0, slog.LevelInfo), level: slog.LevelInfo},
+			want: false,
+		},
+		"happy-path: rate 1": {
+			args: args{sampling: newSampling(1, slog.LevelInfo), level: slog.LevelInfo},
+			want: true,
+		},
+		"happy-path: rate clamped": {
+			args: args{sampling: newSampling(-1, slog.LevelInfo), level: slog.LevelDebug},
+			want: false,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := tt.args.sampling.keep(tt.args.level); got != tt.want {
+				t.Errorf("keep() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransactionalHandler_WithSampling(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(NewTransactionalHandler(nil, nil,
+		WithInnerWriter(buf),
+		WithSlogHandlerSpecify(true, nil),
+		WithSampling(0, slog.LevelInfo)))
+	ctx := context.Background()
+	logger.InfoContext(ctx, "sampled out")
+	logger.WarnContext(ctx, "kept")
+
+	lines := testHelper_JSONLines(t, buf)
+	if len(lines) != 1 || lines[0][slog.MessageKey] != "kept" {
+		t.Errorf("logged %v, want only %q", lines, "kept")
+	}
+}
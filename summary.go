@@ -0,0 +1,132 @@
+package altnrslog
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+const (
+	// KeyLogCountDebug is the attribute key of the number of records below [slog.LevelInfo] in the summary.
+	KeyLogCountDebug = "log.count.debug"
+	// KeyLogCountInfo is the attribute key of the number of records at [slog.LevelInfo] or above, below [slog.LevelWarn].
+	KeyLogCountInfo = "log.count.info"
+	// KeyLogCountWarn is the attribute key of the number of records at [slog.LevelWarn] or above, below [slog.LevelError].
+	KeyLogCountWarn = "log.count.warn"
+	// KeyLogCountError is the attribute key of the number of records at [slog.LevelError] or above.
+	KeyLogCountError = "log.count.error"
+	// KeyLogSampledOut is the attribute key of the number of records dropped by [WithSampling] in the summary.
+	KeyLogSampledOut = "log.sampled_out"
+	// KeyLogDropped is the attribute key of the number of records buffered by [WithTailBuffering] but not written.
+	KeyLogDropped = "log.dropped"
+	// KeyLogFirstError is the attribute key of the message of the first record at [slog.LevelError] or above.
+	// If the record has an attribute with an error value, its message is used.
+	KeyLogFirstError = "log.first_error"
+	// KeyDurationMillis is the attribute key of the duration of the transaction in milliseconds in the summary.
+	KeyDurationMillis = "transaction.duration_ms"
+)
+
+// summaryMessage is the message of the summary record.
+const summaryMessage = "transaction summary"
+
+// WithTransactionSummary specifies that [EndTransaction] emits a summary record of the transaction at [slog.LevelInfo],
+// with the number of the records by level, the numbers of the sampled out and the dropped records,
+// the first error message and the duration. They are also added to the transaction as custom attributes,
+// so that the transactions can be queried by them in NRQL.
+//
+// The summary record is emitted regardless of the levels, and is not buffered, sampled nor counted.
+func WithTransactionSummary() HandlerOption {
+	return func(p *Properties) {
+		p.transactionSummary = true
+	}
+}
+
+// EndTransaction drains the handler of [*slog.Logger] stored in ctx, emits the summary record
+// if [WithTransactionSummary] is specified, and ends the transaction the handler is bound to,
+// so that no records are orphaned after the transaction ends.
+//
+// The records buffered by [WithTailBuffering] are discarded unless the transaction errored.
+// If ctx has no [*slog.Logger] with [*TransactionalHandler], the [newrelic.Transaction] in ctx is ended,
+// and [ErrNotStored] is returned.
+func EndTransaction(ctx context.Context) error {
+	logger, err := FromContext(ctx)
+	if err != nil {
+		newrelic.FromContext(ctx).End()
+		return err
+	}
+	h := logger.Handler().(*TransactionalHandler)
+	if h.state != nil && h.state.buffer != nil {
+		h.state.buffer.discard()
+	}
+	tx := h.Transaction()
+	if h.factory != nil && h.factory.props.transactionSummary && h.state != nil {
+		err = h.emitSummary(ctx, tx)
+	}
+	tx.End()
+	return err
+}
+
+// emitSummary writes the summary record of the transaction, and adds its attributes to tx.
+func (h *TransactionalHandler) emitSummary(ctx context.Context, tx *newrelic.Transaction) error {
+	r := slog.NewRecord(time.Now(), slog.LevelInfo, summaryMessage, 0)
+	attrs := h.state.summaryAttrs(r.Time)
+	for _, a := range attrs {
+		tx.AddAttribute(a.Key, a.Value.Any())
+	}
+	r.AddAttrs(attrs...)
+	h.addLinkingAttrs(ctx, &r)
+	return h.write(ctx, r)
+}
+
+// levelIndex returns the index of the counter of level.
+func levelIndex(level slog.Level) int {
+	switch {
+	case level < slog.LevelInfo:
+		return 0
+	case level < slog.LevelWarn:
+		return 1
+	case level < slog.LevelError:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// observe counts r, and keeps its message if it is the first error.
+func (s *transactionState) observe(r slog.Record) {
+	s.counts[levelIndex(r.Level)].Add(1)
+	if r.Level < slog.LevelError || s.firstError.Load() != nil {
+		return
+	}
+	msg := r.Message
+	r.Attrs(func(a slog.Attr) bool {
+		if err := errorOf(a); err != nil {
+			msg = err.Error()
+			return false
+		}
+		return true
+	})
+	s.firstError.CompareAndSwap(nil, &msg)
+}
+
+// summaryAttrs returns the attributes of the summary record at now.
+func (s *transactionState) summaryAttrs(now time.Time) []slog.Attr {
+	var dropped int64
+	if s.buffer != nil {
+		dropped = s.buffer.droppedCount()
+	}
+	attrs := []slog.Attr{
+		slog.Int64(KeyLogCountDebug, s.counts[0].Load()),
+		slog.Int64(KeyLogCountInfo, s.counts[1].Load()),
+		slog.Int64(KeyLogCountWarn, s.counts[2].Load()),
+		slog.Int64(KeyLogCountError, s.counts[3].Load()),
+		slog.Int64(KeyLogSampledOut, s.sampledOut.Load()),
+		slog.Int64(KeyLogDropped, dropped),
+	}
+	if msg := s.firstError.Load(); msg != nil {
+		attrs = append(attrs, slog.String(KeyLogFirstError, *msg))
+	}
+	return append(attrs, slog.Int64(KeyDurationMillis, now.Sub(s.startedAt).Milliseconds()))
+}
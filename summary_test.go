@@ -0,0 +1,151 @@
+package altnrslog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+func TestEndTransaction(t *testing.T) {
+	buf := &bytes.Buffer{}
+	app := testHelper_Application(t)
+	tx := app.StartTransaction("test")
+	ctx := newrelic.NewContext(context.Background(), tx)
+	logger := slog.New(NewTransactionalHandler(app, tx,
+		WithInnerHandlerProvider(func(io.Writer) slog.Handler {
+			return slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})
+		}),
+		WithLogLevel(slog.LevelWarn),
+		WithTailBuffering(slog.LevelWarn, 0),
+		WithTransactionSummary()))
+	ctx, _ = StoreToContext(ctx, logger)
+
+	logger.DebugContext(ctx, "disabled")
+	logger.WarnContext(ctx, "warn 1")
+	logger.With("user", "foo").WarnContext(ctx, "warn 2")
+	logger.ErrorContext(ctx, "error")
+	if err := EndTransaction(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := testHelper_JSONLines(t, buf)
+	if len(lines) != 4 {
+		t.Fatalf("logged %d records, want 4: %v", len(lines), lines)
+	}
+	summary := lines[3]
+	if summary[slog.MessageKey] != summaryMessage || summary[slog.LevelKey] != "INFO" {
+		t.Fatalf("last record = %v, want the summary", summary)
+	}
+	for k, want := range map[string]float64{
+		KeyLogCountDebug: 0,
+		KeyLogCountInfo:  0,
+		KeyLogCountWarn:  2,
+		KeyLogCountError: 1,
+	} {
+		if summary[k] != want {
+			t.Errorf("%s = %v, want %v", k, summary[k], want)
+		}
+	}
+	if _, ok := summary[KeyDurationMillis].(float64); !ok {
+		t.Errorf("%s = %v, want a number", KeyDurationMillis, summary[KeyDurationMillis])
+	}
+	if _, ok := summary["trace.id"]; !ok {
+		t.Error("summary not linked to the transaction")
+	}
+}
+
+func TestEndTransaction_SummaryDroppedAndFirstError(t *testing.T) {
+	buf := &bytes.Buffer{}
+	app := testHelper_Application(t)
+	tx := app.StartTransaction("test")
+	ctx := newrelic.NewContext(context.Background(), tx)
+	logger := slog.New(NewTransactionalHandler(app, tx,
+		testHelper_BufferOption(buf),
+		WithTailBuffering(slog.LevelWarn, 1),
+		WithTransactionSummary()))
+	ctx, _ = StoreToContext(ctx, logger)
+
+	logger.InfoContext(ctx, "dropped beyond the limit")
+	logger.InfoContext(ctx, "written by the error")
+	logger.ErrorContext(ctx, "failed", slog.Any("error", errors.New("first error")))
+	logger.ErrorContext(ctx, "second error")
+	if err := EndTransaction(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := testHelper_JSONLines(t, buf)
+	summary := lines[len(lines)-1]
+	want := map[string]any{
+		KeyLogCountInfo:  float64(2),
+		KeyLogCountError: float64(2),
+		KeyLogSampledOut: float64(0),
+		KeyLogDropped:    float64(1),
+		KeyLogFirstError: "first error",
+	}
+	for k, v := range want {
+		if summary[k] != v {
+			t.Errorf("%s = %v, want %v", k, summary[k], v)
+		}
+	}
+}
+
+func TestEndTransaction_SummarySampledOut(t *testing.T) {
+	buf := &bytes.Buffer{}
+	app := testHelper_Application(t)
+	tx := app.StartTransaction("test")
+	ctx := newrelic.NewContext(context.Background(), tx)
+	logger := slog.New(NewTransactionalHandler(app, tx,
+		testHelper_BufferOption(buf),
+		WithSampling(0, slog.LevelInfo),
+		WithTransactionSummary()))
+	ctx, _ = StoreToContext(ctx, logger)
+
+	logger.InfoContext(ctx, "sampled out 1")
+	logger.InfoContext(ctx, "sampled out 2")
+	if err := EndTransaction(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := testHelper_JSONLines(t, buf)
+	if len(lines) != 1 {
+		t.Fatalf("logged %d records, want only the summary", len(lines))
+	}
+	if got := lines[0][KeyLogSampledOut]; got != float64(2) {
+		t.Errorf("%s = %v, want %v", KeyLogSampledOut, got, 2)
+	}
+	if _, ok := lines[0][KeyLogFirstError]; ok {
+		t.Errorf("%s = %v, want none", KeyLogFirstError, lines[0][KeyLogFirstError])
+	}
+}
+
+func TestEndTransaction_DiscardsBuffer(t *testing.T) {
+	buf := &bytes.Buffer{}
+	ctx := testHelper_LoggerContext(t, buf)
+	logger, _ := FromContext(ctx)
+	h := logger.Handler().(*TransactionalHandler)
+	h.state.buffer = newTailBuffer(&tailBuffering{threshold: slog.LevelError})
+
+	logger.WarnContext(ctx, "buffered")
+	if err := EndTransaction(ctx); err != nil {
+		t.Fatal(err)
+	}
+	logger.ErrorContext(ctx, "after end")
+
+	lines := testHelper_JSONLines(t, buf)
+	if len(lines) != 1 || lines[0][slog.MessageKey] != "after end" {
+		t.Errorf("logged %v, want only %q", lines, "after end")
+	}
+}
+
+func TestEndTransaction_NotStored(t *testing.T) {
+	app := testHelper_Application(t)
+	ctx := newrelic.NewContext(context.Background(), app.StartTransaction("test"))
+	if err := EndTransaction(ctx); !errors.Is(err, ErrNotStored) {
+		t.Errorf("EndTransaction() error = %v, want %v", err, ErrNotStored)
+	}
+}
@@ -0,0 +1,138 @@
+package altnrslog
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+)
+
+// tailBuffering is the setting of the tail-based buffering.
+type tailBuffering struct {
+	threshold  slog.Level
+	maxRecords int
+}
+
+// WithTailBuffering specifies that the records below threshold are buffered per transaction in memory,
+// and written only if a record at [slog.LevelError] or above is handled, or [MarkErrored] is called,
+// before the transaction ends. Otherwise they are discarded, so the detailed context is kept only for failed transactions.
+//
+// At most maxRecords records are buffered per transaction, and the oldest ones are dropped beyond it.
+// if maxRecords is zero or negative, the number is not limited.
+//
+// The records to be buffered must be enabled by [WithLogLevel] and the level of the inner handler,
+// such as [slog.LevelDebug] with threshold [slog.LevelInfo].
+// The transactions escalated by [EnableDebug] are not buffered.
+func WithTailBuffering(threshold slog.Level, maxRecords int) HandlerOption {
+	return func(p *Properties) {
+		p.tailBuffering = &tailBuffering{threshold: threshold, maxRecords: maxRecords}
+	}
+}
+
+// bufferedRecord is a record buffered with the handler to write it.
+type bufferedRecord struct {
+	h   *TransactionalHandler
+	ctx context.Context
+	r   slog.Record
+}
+
+// tailBuffer buffers the records of a transaction until an error occurs.
+type tailBuffer struct {
+	props *tailBuffering
+
+	mu      sync.Mutex
+	records []bufferedRecord
+	errored bool
+	dropped int64
+}
+
+// newTailBuffer is constructor for tailBuffer.
+func newTailBuffer(props *tailBuffering) *tailBuffer {
+	return &tailBuffer{props: props}
+}
+
+// handle buffers r if it is below the threshold and no error has occurred, otherwise writes it by h.
+// A record at [slog.LevelError] or above flushes the buffered records before it.
+//
+// The records are written without b.mu held, so that a record logged while formatting another one,
+// such as by a [slog.LogValuer], does not deadlock.
+func (b *tailBuffer) handle(ctx context.Context, h *TransactionalHandler, r slog.Record) error {
+	b.mu.Lock()
+	if b.errored {
+		b.mu.Unlock()
+		return h.write(ctx, r)
+	}
+	if r.Level < b.props.threshold {
+		if b.props.maxRecords > 0 && len(b.records) >= b.props.maxRecords {
+			b.records[0] = bufferedRecord{}
+			b.records = b.records[1:]
+			b.dropped++
+		}
+		b.records = append(b.records, bufferedRecord{h: h, ctx: context.WithoutCancel(ctx), r: r.Clone()})
+		b.mu.Unlock()
+		return nil
+	}
+	if r.Level < slog.LevelError {
+		b.mu.Unlock()
+		return h.write(ctx, r)
+	}
+	records := b.takeLocked()
+	b.mu.Unlock()
+	return errors.Join(writeBuffered(records), h.write(ctx, r))
+}
+
+// flush writes the buffered records, and lets the following records through.
+func (b *tailBuffer) flush() error {
+	b.mu.Lock()
+	records := b.takeLocked()
+	b.mu.Unlock()
+	return writeBuffered(records)
+}
+
+// takeLocked marks the buffer as errored and takes the buffered records out of it, with b.mu held.
+func (b *tailBuffer) takeLocked() []bufferedRecord {
+	b.errored = true
+	records := b.records
+	b.records = nil
+	return records
+}
+
+// writeBuffered writes the buffered records by their handlers.
+func writeBuffered(records []bufferedRecord) error {
+	var errs []error
+	for _, br := range records {
+		errs = append(errs, br.h.write(br.ctx, br.r))
+	}
+	return errors.Join(errs...)
+}
+
+// discard discards the buffered records.
+func (b *tailBuffer) discard() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.dropped += int64(len(b.records))
+	b.records = nil
+}
+
+// droppedCount returns the number of the records dropped beyond the limit or discarded.
+func (b *tailBuffer) droppedCount() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.dropped
+}
+
+// MarkErrored marks the transaction of [*slog.Logger] stored in ctx as errored,
+// so that the records buffered by [WithTailBuffering] are written, and the following records are not buffered.
+//
+// If ctx has no [*slog.Logger] with [*TransactionalHandler], [ErrNotStored] is returned.
+func MarkErrored(ctx context.Context) error {
+	logger, err := FromContext(ctx)
+	if err != nil {
+		return err
+	}
+	h := logger.Handler().(*TransactionalHandler)
+	if h.state == nil || h.state.buffer == nil {
+		return nil
+	}
+	return h.state.buffer.flush()
+}
@@ -0,0 +1,167 @@
+package altnrslog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+func TestWithTailBuffering(t *testing.T) {
+	type args struct {
+		maxRecords int
+		logs       func(ctx context.Context, logger *slog.Logger)
+	}
+	type test struct {
+		args args
+		want []string
+	}
+	tests := map[string]test{
+		"happy-path: discarded without error": {
+			args: args{
+				logs: func(ctx context.Context, logger *slog.Logger) {
+					logger.DebugContext(ctx, "debug")
+					logger.InfoContext(ctx, "info")
+				},
+			},
+			want: []string{"info"},
+		},
+		"happy-path: flushed by error": {
+			args: args{
+				logs: func(ctx context.Context, logger *slog.Logger) {
+					logger.DebugContext(ctx, "debug 1")
+					logger.InfoContext(ctx, "info")
+					logger.With("user", "foo").DebugContext(ctx, "debug 2")
+					logger.ErrorContext(ctx, "error")
+					logger.DebugContext(ctx, "debug 3")
+				},
+			},
+			want: []string{"info", "debug 1", "debug 2", "error", "debug 3"},
+		},
+		"happy-path: flushed by MarkErrored": {
+			args: args{
+				logs: func(ctx context.Context, logger *slog.Logger) {
+					logger.DebugContext(ctx, "debug 1")
+					ctx, _ = StoreToContext(ctx, logger)
+					if err := MarkErrored(ctx); err != nil {
+						panic(err)
+					}
+					logger.DebugContext(ctx, "debug 2")
+				},
+			},
+			want: []string{"debug 1", "debug 2"},
+		},
+		"happy-path: oldest dropped": {
+			args: args{
+				maxRecords: 2,
+				logs: func(ctx context.Context, logger *slog.Logger) {
+					logger.DebugContext(ctx, "debug 1")
+					logger.DebugContext(ctx, "debug 2")
+					logger.DebugContext(ctx, "debug 3")
+					logger.ErrorContext(ctx, "error")
+				},
+			},
+			want: []string{"debug 2", "debug 3", "error"},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			logger := slog.New(NewTransactionalHandler(nil, nil,
+				WithInnerHandlerProvider(func(io.Writer) slog.Handler {
+					return slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})
+				}),
+				WithMetadataProvider(&fakeMetadataProvider{}),
+				WithLogLevel(slog.LevelDebug),
+				WithTailBuffering(slog.LevelInfo, tt.args.maxRecords)))
+			tt.args.logs(context.Background(), logger)
+
+			var got []string
+			for _, line := range testHelper_JSONLines(t, buf) {
+				got = append(got, line[slog.MessageKey].(string))
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("logged %v, want %v", got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("logged %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestWithTailBuffering_KeepsLinkingMetadata(t *testing.T) {
+	buf := &bytes.Buffer{}
+	provider := &fakeMetadataProvider{md: newrelic.LinkingMetadata{TraceID: "trace", SpanID: "span-1"}}
+	logger := slog.New(NewTransactionalHandler(nil, nil,
+		testHelper_BufferOption(buf),
+		WithMetadataProvider(provider),
+		WithTailBuffering(slog.LevelError, 0)))
+	ctx := context.Background()
+	logger.InfoContext(ctx, "info")
+	provider.md.SpanID = "span-2"
+	logger.ErrorContext(ctx, "error")
+
+	lines := testHelper_JSONLines(t, buf)
+	if len(lines) != 2 {
+		t.Fatalf("logged %d records, want 2", len(lines))
+	}
+	if got := lines[0]["span.id"]; got != "span-1" {
+		t.Errorf("span.id of buffered record = %v, want %v", got, "span-1")
+	}
+	if got := lines[1]["span.id"]; got != "span-2" {
+		t.Errorf("span.id = %v, want %v", got, "span-2")
+	}
+}
+
+func TestWithTailBuffering_LoggingDuringFormatting(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(NewTransactionalHandler(nil, nil,
+		WithInnerHandlerProvider(func(io.Writer) slog.Handler {
+			return slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})
+		}),
+		WithLogLevel(slog.LevelDebug),
+		WithTailBuffering(slog.LevelInfo, 10)))
+	valuer := func(msg string) testLogValuerFunc {
+		return func() slog.Value {
+			logger.Debug(msg)
+			return slog.StringValue("resolved")
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		logger.Debug("buffered", slog.Any("v", valuer("nested in buffered")))
+		logger.Error("boom", slog.Any("v", valuer("nested in error")))
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("logging during formatting deadlocked")
+	}
+
+	lines := testHelper_JSONLines(t, buf)
+	var got []string
+	for _, l := range lines {
+		got = append(got, l[slog.MessageKey].(string))
+	}
+	want := []string{"nested in buffered", "buffered", "nested in error", "boom"}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestMarkErrored_NotStored(t *testing.T) {
+	if err := MarkErrored(context.Background()); !errors.Is(err, ErrNotStored) {
+		t.Errorf("MarkErrored() error = %v, want %v", err, ErrNotStored)
+	}
+}
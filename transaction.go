@@ -0,0 +1,119 @@
+package altnrslog
+
+import (
+	"context"
+	"log/slog"
+	"runtime/debug"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+// RunInTransaction starts a new [newrelic.Transaction] named name and calls fn with [context.Context]
+// storing the transaction and [*slog.Logger] with [*TransactionalHandler] bound to it.
+//
+// The error returned by fn is recorded with [newrelic.Transaction.NoticeError], and marks the transaction
+// as errored like [MarkErrored].
+// If fn panics, the panic is recovered like [RecoverAndLog], and returned as an error wrapping [ErrPanicked].
+// The transaction is ended by [EndTransaction] when fn returns.
+func RunInTransaction(ctx context.Context, app *newrelic.Application, name string, fn func(ctx context.Context, logger *slog.Logger) error, options ...HandlerOption) (err error) {
+	tx := app.StartTransaction(name)
+	ctx = newrelic.NewContext(ctx, tx)
+	logger := slog.New(NewTransactionalHandler(app, tx, options...))
+	// StoreToContext never fails for the logger with TransactionalHandler.
+	ctx, _ = StoreToContext(ctx, logger)
+	defer EndTransaction(ctx)
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = notePanic(ctx, r, debug.Stack())
+		}
+	}()
+
+	if err = fn(ctx, logger); err != nil {
+		tx.NoticeError(err)
+		MarkErrored(ctx)
+	}
+	return err
+}
+
+// Go calls fn in a new goroutine with [context.Context] for it.
+//
+// The [newrelic.Transaction] in ctx is cloned with [newrelic.Transaction.NewGoroutine],
+// and [*slog.Logger] stored in ctx is re-bound to the clone and stored in the context for the goroutine,
+// so segments and logs in the goroutine are linked correctly.
+func Go(ctx context.Context, fn func(ctx context.Context)) {
+	ctx = goroutineContext(ctx)
+	go fn(ctx)
+}
+
+// goroutineContext returns the context for a new goroutine.
+// It must be called in the parent goroutine.
+func goroutineContext(ctx context.Context) context.Context {
+	logger, err := FromContext(ctx)
+	if err != nil {
+		if tx := newrelic.FromContext(ctx); tx != nil {
+			return newrelic.NewContext(ctx, tx.NewGoroutine())
+		}
+		return ctx
+	}
+	h := logger.Handler().(*TransactionalHandler)
+	tx := h.Transaction().NewGoroutine()
+	if tx == nil {
+		return ctx
+	}
+	ctx = newrelic.NewContext(ctx, tx)
+	// StoreToContext never fails for the logger with TransactionalHandler.
+	ctx, _ = StoreToContext(ctx, slog.New(h.withGoroutine(tx)))
+	return ctx
+}
+
+// KeySegmentName is the attribute key of the segment name added by [StartSegment].
+const KeySegmentName = "segment.name"
+
+// StartSegment starts a [newrelic.Segment] named name in the transaction in ctx, and returns [context.Context]
+// storing [*slog.Logger] whose records carry the segment name and the span ID of the segment,
+// the logger, and the function to end the segment.
+//
+// If ctx has no [*slog.Logger] with [*TransactionalHandler], the returned logger is based on [slog.Default].
+func StartSegment(ctx context.Context, name string) (context.Context, *slog.Logger, func()) {
+	logger, err := FromContext(ctx)
+	if err != nil {
+		seg := newrelic.FromContext(ctx).StartSegment(name)
+		return ctx, slog.Default().With(slog.String(KeySegmentName, name)), seg.End
+	}
+	h := logger.Handler().(*TransactionalHandler)
+	tx := h.Transaction()
+	seg := tx.StartSegment(name)
+	pinned := &segmentMetadataProvider{
+		tx:     tx,
+		spanID: tx.GetTraceMetadata().SpanID,
+	}
+	logger = slog.New(h.withMetadataProvider(pinned)).With(slog.String(KeySegmentName, name))
+	// StoreToContext never fails for the logger with TransactionalHandler.
+	ctx, _ = StoreToContext(ctx, logger)
+	return ctx, logger, seg.End
+}
+
+// segmentMetadataProvider is a [MetadataProvider] that pins the span ID to the one of a segment.
+type segmentMetadataProvider struct {
+	tx     *newrelic.Transaction
+	spanID string
+}
+
+// GetLinkingMetadata See: [MetadataProvider.GetLinkingMetadata]
+func (p *segmentMetadataProvider) GetLinkingMetadata() newrelic.LinkingMetadata {
+	md := p.tx.GetLinkingMetadata()
+	if p.spanID != "" {
+		md.SpanID = p.spanID
+	}
+	return md
+}
+
+// GetTraceMetadata returns the trace metadata of the transaction with the span ID of the segment.
+func (p *segmentMetadataProvider) GetTraceMetadata() newrelic.TraceMetadata {
+	tm := p.tx.GetTraceMetadata()
+	if p.spanID != "" {
+		tm.SpanID = p.spanID
+	}
+	return tm
+}
@@ -0,0 +1,34 @@
+package altnrslog
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// transactionState is the state of a transaction shared by the handlers bound to it,
+// including the ones derived by [TransactionalHandler.WithAttrs], [TransactionalHandler.WithGroup], [Go] and [StartSegment].
+type transactionState struct {
+	debug     atomic.Bool
+	buffer    *tailBuffer
+	startedAt time.Time
+
+	counts     [4]atomic.Int64
+	sampledOut atomic.Int64
+	firstError atomic.Pointer[string]
+}
+
+// newTransactionState is constructor for transactionState.
+// props may be nil for the handlers created without [HandlerFactory].
+func newTransactionState(props *Properties) *transactionState {
+	s := &transactionState{}
+	s.init(props)
+	return s
+}
+
+// init initializes the state of the transaction starting now.
+func (s *transactionState) init(props *Properties) {
+	s.startedAt = time.Now()
+	if props != nil && props.tailBuffering != nil {
+		s.buffer = newTailBuffer(props.tailBuffering)
+	}
+}
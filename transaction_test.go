@@ -0,0 +1,307 @@
+package altnrslog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+func testHelper_Application(t *testing.T) *newrelic.Application {
+	t.Helper()
+	app, err := newrelic.NewApplication(
+		newrelic.ConfigAppName("altnrslog-test"),
+		newrelic.ConfigLicense("0123456789012345678901234567890123456789"),
+		newrelic.ConfigEnabled(false),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return app
+}
+
+func testHelper_JSONLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var lines []map[string]any
+	for _, l := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if l == "" {
+			continue
+		}
+		var m map[string]any
+		if err := json.Unmarshal([]byte(l), &m); err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, m)
+	}
+	return lines
+}
+
+func testHelper_BufferOption(buf *bytes.Buffer) HandlerOption {
+	return WithInnerHandlerProvider(func(io.Writer) slog.Handler {
+		return slog.NewJSONHandler(buf, nil)
+	})
+}
+
+func TestRunInTransaction(t *testing.T) {
+	errTest := errors.New("test")
+	type test struct {
+		fn       func(ctx context.Context, logger *slog.Logger) error
+		wantErr  error
+		wantMsgs []string
+	}
+	tests := map[string]test{
+		"happy-path": {
+			fn: func(ctx context.Context, logger *slog.Logger) error {
+				logger.InfoContext(ctx, "working")
+				return nil
+			},
+			wantMsgs: []string{"working"},
+		},
+		"unhappy-path: error": {
+			fn: func(ctx context.Context, logger *slog.Logger) error {
+				return errTest
+			},
+			wantErr: errTest,
+		},
+		"unhappy-path: panic": {
+			fn: func(ctx context.Context, logger *slog.Logger) error {
+				panic("boom")
+			},
+			wantErr:  ErrPanicked,
+			wantMsgs: []string{"panic recovered"},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			app := testHelper_Application(t)
+			buf := &bytes.Buffer{}
+			var gotTx *newrelic.Transaction
+			err := RunInTransaction(context.Background(), app, "job", func(ctx context.Context, logger *slog.Logger) error {
+				gotTx = newrelic.FromContext(ctx)
+				stored, err := FromContext(ctx)
+				if err != nil || stored != logger {
+					t.Errorf("FromContext() = %v, %v, want %v", stored, err, logger)
+				}
+				return tt.fn(ctx, logger)
+			}, testHelper_BufferOption(buf))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("RunInTransaction() error = %v, want %v", err, tt.wantErr)
+			}
+			if gotTx == nil {
+				t.Fatal("transaction not stored in context")
+			}
+			if md := gotTx.GetTraceMetadata(); md.TraceID != "" {
+				t.Error("transaction not ended")
+			}
+			lines := testHelper_JSONLines(t, buf)
+			if len(lines) != len(tt.wantMsgs) {
+				t.Fatalf("logged %d records, want %d", len(lines), len(tt.wantMsgs))
+			}
+			for i, l := range lines {
+				if l[slog.MessageKey] != tt.wantMsgs[i] {
+					t.Errorf("msg = %v, want %v", l[slog.MessageKey], tt.wantMsgs[i])
+				}
+			}
+			if tt.wantErr == ErrPanicked {
+				if !strings.Contains(lines[0][KeyStack].(string), "runtime/debug.Stack") {
+					t.Errorf("stack = %v, want stack trace", lines[0][KeyStack])
+				}
+				if lines[0][slog.LevelKey] != slog.LevelError.String() {
+					t.Errorf("level = %v, want %v", lines[0][slog.LevelKey], slog.LevelError)
+				}
+			}
+		})
+	}
+}
+
+func TestRunInTransaction_WithTailBuffering(t *testing.T) {
+	type test struct {
+		err      error
+		wantMsgs []string
+	}
+	tests := map[string]test{
+		"happy-path: discarded": {},
+		"unhappy-path: flushed by error": {
+			err:      errors.New("test"),
+			wantMsgs: []string{"buffered"},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			RunInTransaction(context.Background(), testHelper_Application(t), "job", func(ctx context.Context, logger *slog.Logger) error {
+				logger.InfoContext(ctx, "buffered")
+				return tt.err
+			}, testHelper_BufferOption(buf), WithTailBuffering(slog.LevelError, 0))
+
+			lines := testHelper_JSONLines(t, buf)
+			if len(lines) != len(tt.wantMsgs) {
+				t.Fatalf("logged %d records, want %d", len(lines), len(tt.wantMsgs))
+			}
+			for i, l := range lines {
+				if l[slog.MessageKey] != tt.wantMsgs[i] {
+					t.Errorf("msg = %v, want %v", l[slog.MessageKey], tt.wantMsgs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGo(t *testing.T) {
+	app := testHelper_Application(t)
+	tx := app.StartTransaction("parent")
+	defer tx.End()
+
+	type test struct {
+		ctx        func() context.Context
+		wantLogger bool
+	}
+	tests := map[string]test{
+		"happy-path: with logger": {
+			ctx: func() context.Context {
+				ctx := newrelic.NewContext(context.Background(), tx)
+				ctx, _ = StoreToContext(ctx, slog.New(NewTransactionalHandler(app, tx)))
+				return ctx
+			},
+			wantLogger: true,
+		},
+		"happy-path: transaction only": {
+			ctx: func() context.Context {
+				return newrelic.NewContext(context.Background(), tx)
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			done := make(chan context.Context)
+			Go(tt.ctx(), func(ctx context.Context) {
+				done <- ctx
+			})
+			ctx := <-done
+
+			childTx := newrelic.FromContext(ctx)
+			if childTx == nil || childTx == tx {
+				t.Fatalf("newrelic.FromContext() = %p, want a goroutine clone of %p", childTx, tx)
+			}
+			if got, want := childTx.GetTraceMetadata().TraceID, tx.GetTraceMetadata().TraceID; got != want {
+				t.Errorf("TraceID = %s, want %s", got, want)
+			}
+			logger, err := FromContext(ctx)
+			if !tt.wantLogger {
+				if !errors.Is(err, ErrNotStored) {
+					t.Errorf("FromContext() error = %v, want %v", err, ErrNotStored)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := logger.Handler().(*TransactionalHandler).Transaction(); got != childTx {
+				t.Errorf("Transaction() = %p, want %p", got, childTx)
+			}
+		})
+	}
+}
+
+func TestGo_WithoutTransaction(t *testing.T) {
+	parent := context.Background()
+	done := make(chan context.Context)
+	Go(parent, func(ctx context.Context) {
+		done <- ctx
+	})
+	if ctx := <-done; ctx != parent {
+		t.Errorf("Go() ctx = %v, want %v", ctx, parent)
+	}
+}
+
+func TestStartSegment(t *testing.T) {
+	app := testHelper_Application(t)
+	tx := app.StartTransaction("parent")
+	defer tx.End()
+	buf := &bytes.Buffer{}
+	parent := slog.New(NewTransactionalHandler(app, tx, testHelper_BufferOption(buf)))
+	ctx, _ := StoreToContext(newrelic.NewContext(context.Background(), tx), parent)
+
+	ctx, logger, end := StartSegment(ctx, "query")
+	logger.InfoContext(ctx, "in segment")
+	end()
+	parent.InfoContext(ctx, "after segment")
+
+	stored, err := FromContext(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored != logger {
+		t.Errorf("FromContext() = %p, want %p", stored, logger)
+	}
+	h := logger.Handler().(*TransactionalHandler)
+	if _, ok := h.metadata.src.(*segmentMetadataProvider); !ok {
+		t.Errorf("metadata.src = %T, want %T", h.metadata.src, &segmentMetadataProvider{})
+	}
+
+	lines := testHelper_JSONLines(t, buf)
+	if len(lines) != 2 {
+		t.Fatalf("logged %d records, want 2", len(lines))
+	}
+	if lines[0][KeySegmentName] != "query" {
+		t.Errorf("%s = %v, want %v", KeySegmentName, lines[0][KeySegmentName], "query")
+	}
+	if _, ok := lines[1][KeySegmentName]; ok {
+		t.Errorf("%s must not be added to the parent logger", KeySegmentName)
+	}
+	if lines[0]["trace.id"] != tx.GetTraceMetadata().TraceID {
+		t.Errorf("trace.id = %v, want %v", lines[0]["trace.id"], tx.GetTraceMetadata().TraceID)
+	}
+}
+
+func TestStartSegment_WithoutLogger(t *testing.T) {
+	parent := context.Background()
+	ctx, logger, end := StartSegment(parent, "query")
+	defer end()
+	if ctx != parent {
+		t.Errorf("StartSegment() ctx = %v, want %v", ctx, parent)
+	}
+	if logger == nil {
+		t.Error("StartSegment() logger = nil")
+	}
+}
+
+func Test_segmentMetadataProvider(t *testing.T) {
+	app := testHelper_Application(t)
+	tx := app.StartTransaction("parent")
+	defer tx.End()
+
+	type test struct {
+		spanID string
+		want   string
+	}
+	tests := map[string]test{
+		"happy-path: pinned span": {
+			spanID: "segment-span-id",
+			want:   "segment-span-id",
+		},
+		"happy-path: unsampled": {
+			want: tx.GetTraceMetadata().SpanID,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			sut := &segmentMetadataProvider{tx: tx, spanID: tt.spanID}
+			if got := sut.GetLinkingMetadata().SpanID; got != tt.want {
+				t.Errorf("GetLinkingMetadata().SpanID = %s, want %s", got, tt.want)
+			}
+			if got := sut.GetTraceMetadata().SpanID; got != tt.want {
+				t.Errorf("GetTraceMetadata().SpanID = %s, want %s", got, tt.want)
+			}
+			if got, want := sut.GetTraceMetadata().TraceID, tx.GetTraceMetadata().TraceID; got != want {
+				t.Errorf("GetTraceMetadata().TraceID = %s, want %s", got, want)
+			}
+		})
+	}
+}
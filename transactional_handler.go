@@ -5,47 +5,337 @@ import (
 	"context"
 	"io"
 	"log/slog"
-	"os"
+	"sync"
+	"sync/atomic"
 
 	"github.com/newrelic/go-agent/v3/integrations/logcontext"
-	"github.com/newrelic/go-agent/v3/integrations/logcontext-v2/logWriter"
 	"github.com/newrelic/go-agent/v3/newrelic"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // TransactionalHandler is a [slog.Handler] that adds New Relic distributed tracing metadata to log records.
 type TransactionalHandler struct {
+	handler  slog.Handler
+	tx       *newrelic.Transaction
+	level    slog.Level
+	factory  *HandlerFactory
+	ops      []handlerOp
+	metadata *metadataCache
+	writer   *recordWriter
+	// spares is the pool of the spare instances of handler, created on the first contention.
+	spares sync.Pool
+	name   string
+	state  *transactionState
+	// redactedBy is the filters the attributes of ops applied to handler are redacted by.
+	redactedBy *filters
+	// rebuilt is the inner handler rebuilt after the live filters have been swapped.
+	rebuilt atomic.Pointer[innerChain]
+}
+
+// innerChain is the inner handler with the operations replayed, redacted by the filters,
+// and the pool of its spare instances.
+type innerChain struct {
 	handler slog.Handler
-	tx      *newrelic.Transaction
-	level   slog.Level
+	filters *filters
+	spares  sync.Pool
+}
+
+// handlerOp is a call to [slog.Handler.WithAttrs] or [slog.Handler.WithGroup] to be replayed on rebinding.
+// The attributes are kept as given, and redacted by the filters when applied.
+type handlerOp struct {
+	attrs []slog.Attr
+	group string
+}
+
+// apply applies the operation to h.
+func (o handlerOp) apply(h slog.Handler) slog.Handler {
+	if o.group != "" {
+		return h.WithGroup(o.group)
+	}
+	return h.WithAttrs(o.attrs)
+}
+
+// redacted returns the operation with the attributes redacted by f.
+func (o handlerOp) redacted(f *filters) handlerOp {
+	if f == nil || len(f.redactKeys) == 0 || len(o.attrs) == 0 {
+		return o
+	}
+	o.attrs, _ = redactAttrs(f.redactKeys, o.attrs)
+	return o
+}
+
+// loggerName returns the value of the last [KeyLogger] attribute added by the operation.
+func (o handlerOp) loggerName() (string, bool) {
+	var (
+		name  string
+		found bool
+	)
+	for _, a := range o.attrs {
+		if a.Key == KeyLogger && a.Value.Kind() == slog.KindString {
+			name, found = a.Value.String(), true
+		}
+	}
+	return name, found
+}
+
+// Transaction returns the [newrelic.Transaction] the handler is bound to.
+func (h *TransactionalHandler) Transaction() *newrelic.Transaction {
+	return h.tx
+}
+
+// Inner returns the wrapped [slog.Handler].
+func (h *TransactionalHandler) Inner() slog.Handler {
+	inner, _, _ := h.inner()
+	return inner
+}
+
+// inner returns the wrapped [slog.Handler], the pool of its spare instances and the filters it is redacted by.
+// If the live filters have been swapped since it was built, it is rebuilt once with the operations
+// redacted by the current filters, so that the attributes added by [slog.Logger.With] are redacted by the new keys.
+func (h *TransactionalHandler) inner() (slog.Handler, *sync.Pool, *filters) {
+	if h.factory == nil || h.factory.props.liveFilters == nil || h.writer == nil {
+		return h.handler, &h.spares, h.redactedBy
+	}
+	f := h.factory.filters()
+	if f == h.redactedBy {
+		return h.handler, &h.spares, f
+	}
+	c := h.rebuilt.Load()
+	if c == nil || c.filters != f {
+		next := &innerChain{handler: h.replay(h.factory.innerHandler(h.writer), f), filters: f}
+		if !h.rebuilt.CompareAndSwap(c, next) {
+			return h.inner()
+		}
+		c = next
+	}
+	return c.handler, &c.spares, c.filters
+}
+
+// replay applies the operations redacted by f to inner.
+func (h *TransactionalHandler) replay(inner slog.Handler, f *filters) slog.Handler {
+	for _, o := range h.ops {
+		inner = o.redacted(f).apply(inner)
+	}
+	return inner
+}
+
+// Level returns the minimum level of records to be handled.
+// With [LevelController], the level is resolved by the logger name of the handler.
+func (h *TransactionalHandler) Level() slog.Level {
+	if h.factory == nil || h.factory.props.leveler == nil {
+		return h.level
+	}
+	if l, ok := h.factory.props.leveler.(namedLeveler); ok {
+		return l.levelFor(h.name)
+	}
+	return h.factory.props.leveler.Level()
+}
+
+// WithTransaction returns a copy of the handler bound to tx.
+// The inner handler is rebuilt with the same options, and the attributes and groups
+// added by [TransactionalHandler.WithAttrs] and [TransactionalHandler.WithGroup] are preserved.
+func (h *TransactionalHandler) WithTransaction(tx *newrelic.Transaction) *TransactionalHandler {
+	if h.factory == nil {
+		return &TransactionalHandler{
+			handler:  h.handler,
+			tx:       tx,
+			level:    h.level,
+			metadata: newMetadataCache(tx),
+			state:    newTransactionState(nil),
+		}
+	}
+	b, inner := h.factory.bind(tx)
+	f := h.factory.filters()
+	return &TransactionalHandler{
+		handler:    h.replay(inner, f),
+		tx:         tx,
+		level:      h.level,
+		factory:    h.factory,
+		ops:        h.ops,
+		metadata:   &b.metadata,
+		writer:     &b.writer,
+		name:       h.name,
+		state:      &b.state,
+		redactedBy: f,
+	}
 }
 
 // Enabled See: [slog.Handler.Enabled]
 func (h *TransactionalHandler) Enabled(ctx context.Context, level slog.Level) bool {
-	return level >= h.level && h.handler.Enabled(ctx, level)
+	if h.escalated(level) {
+		return true
+	}
+	return level >= h.Level() && h.handler.Enabled(ctx, level)
 }
 
 // Handle adds New Relic distributed tracing metadata to log records before passing them to the wrapped handler.
 func (h *TransactionalHandler) Handle(ctx context.Context, r slog.Record) error {
-	md := h.tx.GetLinkingMetadata()
-	r.AddAttrs(attrsFromMetadata(md)...)
-	return h.handler.Handle(ctx, r)
+	if h.state != nil {
+		h.state.observe(r)
+	}
+	if h.factory != nil {
+		var ok bool
+		if r, ok = h.factory.filters().apply(r); !ok {
+			if h.state != nil {
+				h.state.sampledOut.Add(1)
+			}
+			return nil
+		}
+	}
+	if h.factory != nil && h.factory.props.errorExpansion {
+		r = expandErrors(r)
+	}
+	if h.factory != nil && h.factory.props.codeAttributes {
+		r.AddAttrs(attrsFromPC(r.PC)...)
+	}
+	h.addLinkingAttrs(ctx, &r)
+	if h.state != nil && h.state.buffer != nil && !h.debugEnabled() {
+		return h.state.buffer.handle(ctx, h, r)
+	}
+	return h.write(ctx, r)
+}
+
+// write passes the record to the wrapped handler, forwarding it to New Relic with the severity and the time.
+func (h *TransactionalHandler) write(ctx context.Context, r slog.Record) error {
+	inner, spares, f := h.inner()
+	if h.writer == nil {
+		return inner.Handle(ctx, r)
+	}
+	severity := h.factory.props.severity(r.Level)
+	if h.writer.acquire(severity, r.Time) {
+		defer h.writer.release()
+		return inner.Handle(ctx, r)
+	}
+	// The writer is held for another record, being written concurrently or logging this one during formatting,
+	// so the record is written by a spare instance of the inner handler instead of waiting.
+	s := h.spare(spares, f)
+	defer spares.Put(s)
+	s.writer.acquire(severity, r.Time)
+	defer s.writer.release()
+	return s.handler.Handle(ctx, r)
+}
+
+// spareInner is an instance of the inner handler with its own recordWriter.
+type spareInner struct {
+	handler slog.Handler
+	writer  *recordWriter
+}
+
+// spare returns a spare instance of the inner handler pooled in spares,
+// or creates it with the operations redacted by f replayed.
+func (h *TransactionalHandler) spare(spares *sync.Pool, f *filters) *spareInner {
+	if s, ok := spares.Get().(*spareInner); ok {
+		return s
+	}
+	w := newRecordWriter(h.writer.fw)
+	return &spareInner{handler: h.replay(h.factory.innerHandler(w), f), writer: w}
+}
+
+// addLinkingAttrs adds the linking metadata of the transaction to r.
+//
+// The built-in handlers inline a group with an empty key, so the attributes are added to them as one,
+// keeping the record within its inline attributes. The inner handlers of [WithInnerHandlerProvider] receive them as they are.
+func (h *TransactionalHandler) addLinkingAttrs(ctx context.Context, r *slog.Record) {
+	attrs := h.linkingAttrs(ctx)
+	if h.factory == nil || h.factory.props.innerHandlerProvider != nil {
+		r.AddAttrs(attrs...)
+		return
+	}
+	r.AddAttrs(slog.Attr{Value: slog.GroupValue(attrs...)})
+}
+
+// linkingAttrs returns the linking metadata of the transaction as [slog.Attr].
+// The returned slice may be shared with other records, so it must not be modified.
+// If the transaction has no trace and [WithOpenTelemetryFallback] is specified,
+// the trace ID and span ID are taken from the OpenTelemetry span in ctx.
+func (h *TransactionalHandler) linkingAttrs(ctx context.Context) []slog.Attr {
+	var cached *cachedMetadata
+	if h.metadata == nil {
+		cached = newCachedMetadata(h.tx.GetLinkingMetadata())
+	} else {
+		cached = h.metadata.load()
+	}
+	if cached.md.TraceID != "" || h.factory == nil || !h.factory.props.openTelemetryFallback {
+		return cached.attrs
+	}
+	tm := traceMetadataFromSpanContext(trace.SpanContextFromContext(ctx))
+	if tm.TraceID == "" {
+		return cached.attrs
+	}
+	md := cached.md
+	md.TraceID = tm.TraceID
+	md.SpanID = tm.SpanID
+	return attrsFromMetadata(md)
 }
 
 // WithAttrs See: [slog.Handler.WithAttrs]
+//
+// The attributes are redacted by the keys of [WithRedactKeys] or [ConfigWatcher],
+// and redacted again by the new keys when [ConfigWatcher] swaps them.
 func (h *TransactionalHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
-	return &TransactionalHandler{
-		h.handler.WithAttrs(attrs),
-		h.tx,
-		h.level,
-	}
+	return h.with(handlerOp{attrs: attrs})
 }
 
 // WithGroup See: [slog.Handler.WithGroup]
 func (h *TransactionalHandler) WithGroup(name string) slog.Handler {
+	return h.with(handlerOp{group: name})
+}
+
+// with returns a copy of the handler with o applied to the inner handler.
+// The logger name is taken from the [KeyLogger] attribute added outside any group.
+func (h *TransactionalHandler) with(o handlerOp) *TransactionalHandler {
+	ops := make([]handlerOp, len(h.ops), len(h.ops)+1)
+	copy(ops, h.ops)
+	name := h.name
+	if n, ok := o.loggerName(); ok && !h.inGroup() {
+		name = n
+	}
+	inner, _, f := h.inner()
 	return &TransactionalHandler{
-		h.handler.WithGroup(name),
-		h.tx,
-		h.level,
+		handler:    o.redacted(f).apply(inner),
+		tx:         h.tx,
+		level:      h.level,
+		factory:    h.factory,
+		ops:        append(ops, o),
+		metadata:   h.metadata,
+		writer:     h.writer,
+		name:       name,
+		state:      h.state,
+		redactedBy: f,
+	}
+}
+
+// inGroup reports whether a group has been opened by [TransactionalHandler.WithGroup].
+func (h *TransactionalHandler) inGroup() bool {
+	for _, o := range h.ops {
+		if o.group != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// withGoroutine returns a copy of the handler bound to tx, which is the transaction for a new goroutine
+// created by [newrelic.Transaction.NewGoroutine], sharing the state of the transaction.
+func (h *TransactionalHandler) withGoroutine(tx *newrelic.Transaction) *TransactionalHandler {
+	derived := h.WithTransaction(tx)
+	derived.state = h.state
+	return derived
+}
+
+// withMetadataProvider returns a copy of the handler getting the linking metadata from provider.
+func (h *TransactionalHandler) withMetadataProvider(provider MetadataProvider) *TransactionalHandler {
+	return &TransactionalHandler{
+		handler:    h.handler,
+		tx:         h.tx,
+		level:      h.level,
+		factory:    h.factory,
+		ops:        h.ops,
+		metadata:   newMetadataCache(provider),
+		writer:     h.writer,
+		name:       h.name,
+		state:      h.state,
+		redactedBy: h.redactedBy,
 	}
 }
 
@@ -53,19 +343,29 @@ type InnerHandlerProvider func(io.Writer) slog.Handler
 
 // Properties is an options for creating a new [TransactionalHandler].
 type Properties struct {
-	innerWriter          io.Writer
-	json                 bool
-	slogHandlerOptions   *slog.HandlerOptions
-	innerHandlerProvider InnerHandlerProvider
-	logLevel             slog.Level
+	innerWriter           io.Writer
+	json                  bool
+	slogHandlerOptions    *slog.HandlerOptions
+	innerHandlerProvider  InnerHandlerProvider
+	logLevel              slog.Level
+	metadataProvider      MetadataProvider
+	openTelemetryFallback bool
+	errorExpansion        bool
+	codeAttributes        bool
+	levelMappings         map[slog.Level]LevelMapping
+	redactKeys            map[string]struct{}
+	sampling              *sampling
+	leveler               slog.Leveler
+	liveFilters           *liveFilters
+	tailBuffering         *tailBuffering
+	transactionSummary    bool
 }
 
 // HandlerOption is a functional option for creating a new [TransactionalHandler].
 type HandlerOption func(*Properties)
 
-// WithInnerWriter specifies the [io.Writer] that wraps [logWriter.logWriter]
-//
-// [logWriter.logWriter]: https://pkg.go.dev/github.com/newrelic/go-agent/v3/integrations/logcontext-v2/logWriter#LogWriter
+// WithInnerWriter specifies the [io.Writer] the records are written to after being forwarded to New Relic.
+// if not specified, the default is [os.Stdout].
 func WithInnerWriter(w io.Writer) HandlerOption {
 	return func(p *Properties) {
 		p.innerWriter = w
@@ -81,6 +381,11 @@ func WithSlogHandlerSpecify(json bool, o *slog.HandlerOptions) HandlerOption {
 }
 
 // WithInnerHandlerProvider specifies the function that provides the [slog.Handler] to be wrapped.
+//
+// The function may be called many times per transaction: for each transaction, by [TransactionalHandler.WithTransaction],
+// for the spare instances writing the records logged concurrently or during formatting, and to rebuild the handler
+// when [ConfigWatcher] swaps the redact keys. So it must return a new handler writing to the given [io.Writer]
+// each time, without side effects such as opening files or registering the handler.
 func WithInnerHandlerProvider(innerHandlerProvider InnerHandlerProvider) HandlerOption {
 	return func(p *Properties) {
 		p.slogHandlerOptions = nil
@@ -97,6 +402,55 @@ func WithLogLevel(level slog.Level) HandlerOption {
 	}
 }
 
+// WithLeveler specifies the log level that can be changed at runtime, such as [*slog.LevelVar].
+// if specified, [WithLogLevel] is ignored, and the level of the inner handler created by this package
+// is lowered so that the changes take effect.
+func WithLeveler(leveler slog.Leveler) HandlerOption {
+	return func(p *Properties) {
+		p.leveler = leveler
+	}
+}
+
+// WithMetadataProvider specifies the [MetadataProvider] to get the linking metadata from.
+// if not specified, the linking metadata will be got from the [newrelic.Transaction] the handler is bound to.
+func WithMetadataProvider(provider MetadataProvider) HandlerOption {
+	return func(p *Properties) {
+		p.metadataProvider = provider
+	}
+}
+
+// WithOpenTelemetryFallback specifies that the trace ID and span ID are taken from
+// the OpenTelemetry span in [context.Context] when the transaction has no trace,
+// so logs remain linked in services instrumented with OpenTelemetry.
+func WithOpenTelemetryFallback() HandlerOption {
+	return func(p *Properties) {
+		p.openTelemetryFallback = true
+	}
+}
+
+// WithErrorExpansion specifies that the first attribute of a record with an error value is expanded into
+// error.message, error.class and error.stack, the attribute names New Relic's Logs UI highlights.
+//
+// The chains joined by [errors.Join] are unwrapped. The stack trace is taken from the wrapped errors that carry one,
+// otherwise captured at the logging call.
+func WithErrorExpansion() HandlerOption {
+	return func(p *Properties) {
+		p.errorExpansion = true
+	}
+}
+
+// WithCodeAttributes specifies that the source location of a record is added as code.function, code.namespace,
+// code.filepath and code.lineno attributes, so that New Relic's Logs UI links the record to code-level metrics.
+//
+// Unlike [slog.HandlerOptions.AddSource], the attributes are added to the record instead of a source group.
+// Like the other attributes of the record, they are qualified by the groups opened by [slog.Logger.WithGroup],
+// where New Relic does not map them, so use [slog.Group] for the attributes to be grouped instead of WithGroup.
+func WithCodeAttributes() HandlerOption {
+	return func(p *Properties) {
+		p.codeAttributes = true
+	}
+}
+
 // buildProperties creates a new Properties with the given options.
 func buildProperties(options []HandlerOption) (props *Properties) {
 	props = &Properties{}
@@ -108,34 +462,10 @@ func buildProperties(options []HandlerOption) (props *Properties) {
 }
 
 // NewTransactionalHandler is constructor for [TransactionalHandler].
+//
+// To create handlers for many transactions with the same options, use [HandlerFactory] instead.
 func NewTransactionalHandler(app *newrelic.Application, tx *newrelic.Transaction, options ...HandlerOption) *TransactionalHandler {
-	p := buildProperties(options)
-	iw := p.innerWriter
-	if iw == nil {
-		iw = os.Stdout
-	}
-	ww := logWriter.New(iw, app)
-	ww = ww.WithTransaction(tx)
-
-	if p.innerHandlerProvider != nil {
-		return &TransactionalHandler{
-			handler: p.innerHandlerProvider(ww),
-			tx:      tx,
-			level:   p.logLevel,
-		}
-	}
-	if p.json {
-		return &TransactionalHandler{
-			handler: slog.NewJSONHandler(ww, p.slogHandlerOptions),
-			tx:      tx,
-			level:   p.logLevel,
-		}
-	}
-	return &TransactionalHandler{
-		handler: slog.NewTextHandler(ww, p.slogHandlerOptions),
-		tx:      tx,
-		level:   p.logLevel,
-	}
+	return NewHandlerFactory(app, options...).New(tx)
 }
 
 // attrsFromMetadata converts New Relic linking metadata to [slog.Attr].
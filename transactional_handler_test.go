@@ -1,17 +1,23 @@
 package altnrslog
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"github.com/google/go-cmp/cmp"
 	mslog "github.com/miyamo2/altnrslog/internal/mock"
 	"github.com/newrelic/go-agent/v3/integrations/logcontext"
 	"github.com/newrelic/go-agent/v3/newrelic"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"go.uber.org/mock/gomock"
 	"io"
 	"log/slog"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 type mockWriter struct{}
@@ -236,8 +242,16 @@ func Test_buildProperties(t *testing.T) {
 				logLevel: slog.LevelWarn,
 			},
 		},
+		"happy-path: WithMetadataProvider": {
+			args: args{
+				options: []HandlerOption{WithMetadataProvider(&fakeMetadataProvider{})},
+			},
+			want: &Properties{
+				metadataProvider: &fakeMetadataProvider{},
+			},
+		},
 	}
-	opt := cmp.AllowUnexported(Properties{})
+	opt := cmp.AllowUnexported(Properties{}, fakeMetadataProvider{})
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
 			got := buildProperties(tt.args.options)
@@ -298,3 +312,281 @@ func testHelper_DummySlogRecord(t *testing.T) slog.Record {
 	r.AddAttrs(attrsFromMetadata(newrelic.LinkingMetadata{})...)
 	return r
 }
+
+func TestTransactionalHandler_Accessors(t *testing.T) {
+	app := newrelic.Application{}
+	tx := newrelic.Transaction{}
+	sut := NewTransactionalHandler(&app, &tx, WithSlogHandlerSpecify(true, nil), WithLogLevel(slog.LevelWarn))
+	if got := sut.Transaction(); got != &tx {
+		t.Errorf("Transaction() = %p, want %p", got, &tx)
+	}
+	if got := sut.Inner(); got != sut.handler {
+		t.Errorf("Inner() = %v, want %v", got, sut.handler)
+	}
+	if got := sut.Level(); got != slog.LevelWarn {
+		t.Errorf("Level() = %v, want %v", got, slog.LevelWarn)
+	}
+}
+
+func TestTransactionalHandler_WithTransaction(t *testing.T) {
+	app := newrelic.Application{}
+	oldTx := newrelic.Transaction{}
+	newTx := newrelic.Transaction{}
+
+	var writers []io.Writer
+	buf := &bytes.Buffer{}
+	provider := func(w io.Writer) slog.Handler {
+		writers = append(writers, w)
+		return slog.NewJSONHandler(buf, &slog.HandlerOptions{
+			ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+				if len(groups) == 0 && a.Key == slog.TimeKey {
+					return slog.Attr{}
+				}
+				return a
+			},
+		})
+	}
+	sut := NewTransactionalHandler(&app, &oldTx, WithInnerHandlerProvider(provider), WithLogLevel(slog.LevelDebug))
+	derived := sut.WithAttrs([]slog.Attr{slog.String("foo", "bar")}).WithGroup("baz")
+
+	got := derived.(*TransactionalHandler).WithTransaction(&newTx)
+	if got.Transaction() != &newTx {
+		t.Errorf("WithTransaction().Transaction() = %p, want %p", got.Transaction(), &newTx)
+	}
+	if got.Level() != slog.LevelDebug {
+		t.Errorf("WithTransaction().Level() = %v, want %v", got.Level(), slog.LevelDebug)
+	}
+	if len(writers) != 2 {
+		t.Fatalf("inner handler built %d times, want 2", len(writers))
+	}
+
+	r := slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)
+	r.AddAttrs(slog.Int("qux", 1))
+	if err := got.Inner().Handle(context.Background(), r); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"level":"INFO","msg":"msg","foo":"bar","baz":{"qux":1}}` + "\n"
+	if diff := cmp.Diff(buf.String(), want); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestTransactionalHandler_WithTransaction_WithoutProperties(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockHandler := mslog.NewMockHandler(mockCtrl)
+	tx := newrelic.Transaction{}
+	sut := &TransactionalHandler{
+		handler: mockHandler,
+		level:   slog.LevelWarn,
+	}
+	got := sut.WithTransaction(&tx)
+	if got.Transaction() != &tx {
+		t.Errorf("WithTransaction().Transaction() = %p, want %p", got.Transaction(), &tx)
+	}
+	if got.Inner() != mockHandler {
+		t.Errorf("WithTransaction().Inner() = %v, want %v", got.Inner(), mockHandler)
+	}
+	if got.Level() != slog.LevelWarn {
+		t.Errorf("WithTransaction().Level() = %v, want %v", got.Level(), slog.LevelWarn)
+	}
+}
+
+func TestTransactionalHandler_Handle_WithOpenTelemetryFallback(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	defer tp.Shutdown(context.Background())
+	ctx, span := tp.Tracer("altnrslog").Start(context.Background(), "operation")
+	defer span.End()
+	sc := span.SpanContext()
+
+	type args struct {
+		ctx      context.Context
+		provider MetadataProvider
+		options  []HandlerOption
+	}
+	type want struct {
+		traceID string
+		spanID  string
+	}
+	type test struct {
+		args args
+		want want
+	}
+	tests := map[string]test{
+		"happy-path: fallback to span context": {
+			args: args{
+				ctx:     ctx,
+				options: []HandlerOption{WithOpenTelemetryFallback()},
+			},
+			want: want{
+				traceID: sc.TraceID().String(),
+				spanID:  sc.SpanID().String(),
+			},
+		},
+		"happy-path: transaction takes precedence": {
+			args: args{
+				ctx: ctx,
+				provider: &fakeMetadataProvider{md: newrelic.LinkingMetadata{
+					TraceID: "nr-trace-id",
+					SpanID:  "nr-span-id",
+				}},
+				options: []HandlerOption{WithOpenTelemetryFallback()},
+			},
+			want: want{
+				traceID: "nr-trace-id",
+				spanID:  "nr-span-id",
+			},
+		},
+		"happy-path: no span in context": {
+			args: args{
+				ctx:     context.Background(),
+				options: []HandlerOption{WithOpenTelemetryFallback()},
+			},
+		},
+		"happy-path: fallback not specified": {
+			args: args{
+				ctx: ctx,
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			options := append([]HandlerOption{WithInnerHandlerProvider(func(io.Writer) slog.Handler {
+				return slog.NewJSONHandler(buf, nil)
+			})}, tt.args.options...)
+			if tt.args.provider != nil {
+				options = append(options, WithMetadataProvider(tt.args.provider))
+			}
+			logger := slog.New(NewTransactionalHandler(nil, nil, options...))
+			logger.InfoContext(tt.args.ctx, "msg")
+
+			var got map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if got[logcontext.KeyTraceID] != tt.want.traceID {
+				t.Errorf("%s = %v, want %v", logcontext.KeyTraceID, got[logcontext.KeyTraceID], tt.want.traceID)
+			}
+			if got[logcontext.KeySpanID] != tt.want.spanID {
+				t.Errorf("%s = %v, want %v", logcontext.KeySpanID, got[logcontext.KeySpanID], tt.want.spanID)
+			}
+		})
+	}
+}
+
+func TestTransactionalHandler_Handle_LinkingAttrsFlat(t *testing.T) {
+	buf := &bytes.Buffer{}
+	var replaced []string
+	sut := NewTransactionalHandler(nil, nil,
+		WithInnerWriter(buf),
+		WithSlogHandlerSpecify(true, &slog.HandlerOptions{
+			ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+				if a.Key == logcontext.KeyTraceID {
+					replaced = append(replaced, strings.Join(append(groups, a.Key), "."))
+				}
+				return a
+			},
+		}),
+		WithMetadataProvider(&fakeMetadataProvider{md: newrelic.LinkingMetadata{TraceID: "trace", SpanID: "span"}}))
+	if err := slog.New(sut).WithGroup("request").Handler().Handle(context.Background(),
+		slog.NewRecord(time.Now(), slog.LevelInfo, "msg", 0)); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"request." + logcontext.KeyTraceID}, replaced); diff != "" {
+		t.Errorf("ReplaceAttr keys mismatch (-want +got):\n%s", diff)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	request, _ := got["request"].(map[string]any)
+	if request[logcontext.KeyTraceID] != "trace" || request[logcontext.KeySpanID] != "span" {
+		t.Errorf("request = %v, want the linking metadata inlined", request)
+	}
+}
+
+func TestTransactionalHandler_Allocs(t *testing.T) {
+	if testing.CoverMode() != "" || raceEnabled {
+		t.Skip("allocations are not stable with coverage or the race detector")
+	}
+	type test struct {
+		json bool
+	}
+	tests := map[string]test{
+		"JSON": {json: true},
+		"Text": {json: false},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			tx := testHelper_Application(t).StartTransaction("allocs")
+			defer tx.End()
+			h := NewTransactionalHandler(nil, tx,
+				WithInnerWriter(io.Discard),
+				WithSlogHandlerSpecify(tt.json, nil),
+				WithMetadataProvider(&fakeMetadataProvider{md: newrelic.LinkingMetadata{TraceID: "trace", SpanID: "span"}}))
+			derived := h.WithAttrs([]slog.Attr{slog.String(KeyLogger, "billing")})
+			// the inner handler alone, writing to New Relic in the same way.
+			bare := h.factory.innerHandler(newRecordWriter(newForwardingWriter(io.Discard, nil, tx))).
+				WithAttrs([]slog.Attr{slog.String(KeyLogger, "billing")})
+			ctx := context.Background()
+			r := slog.NewRecord(time.Now(), slog.LevelInfo, "msg", 0)
+			r.AddAttrs(slog.String("user", "foo"), slog.Int("n", 1))
+
+			if got := testing.AllocsPerRun(100, func() { derived.Enabled(ctx, slog.LevelDebug) }); got != 0 {
+				t.Errorf("Enabled: allocations = %v, want 0", got)
+			}
+			want := testing.AllocsPerRun(100, func() { bare.Handle(ctx, r) })
+			if got := testing.AllocsPerRun(100, func() { derived.Handle(ctx, r) }); got != want {
+				t.Errorf("Handle: allocations = %v, want %v as the inner handler", got, want)
+			}
+		})
+	}
+}
+
+func benchmarkHelper_Handle(b *testing.B, json bool, parallel bool) {
+	b.Helper()
+	tx := benchmarkHelper_Transaction(b)
+	logger := slog.New(NewTransactionalHandler(nil, tx,
+		WithInnerWriter(io.Discard),
+		WithSlogHandlerSpecify(json, nil)))
+	logger = logger.With(slog.String("service", "billing"))
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	if !parallel {
+		for i := 0; i < b.N; i++ {
+			logger.LogAttrs(ctx, slog.LevelInfo, "msg", slog.String("user", "foo"), slog.Int("n", i))
+		}
+		return
+	}
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			logger.LogAttrs(ctx, slog.LevelInfo, "msg", slog.String("user", "foo"), slog.Int("n", i))
+		}
+	})
+}
+
+func BenchmarkHandle_JSON(b *testing.B) {
+	benchmarkHelper_Handle(b, true, false)
+}
+
+func BenchmarkHandle_Text(b *testing.B) {
+	benchmarkHelper_Handle(b, false, false)
+}
+
+func BenchmarkHandle_JSON_Parallel(b *testing.B) {
+	benchmarkHelper_Handle(b, true, true)
+}
+
+func BenchmarkHandle_Disabled(b *testing.B) {
+	tx := benchmarkHelper_Transaction(b)
+	logger := slog.New(NewTransactionalHandler(nil, tx, WithInnerWriter(io.Discard)))
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.LogAttrs(ctx, slog.LevelDebug, "msg", slog.String("user", "foo"))
+	}
+}